@@ -1,7 +1,9 @@
 package kids1
 
 import (
+	"fmt"
 	golog "log"
+	"time"
 
 	"github.com/l2cup/kids1/pkg/config"
 	"github.com/l2cup/kids1/pkg/crawler"
@@ -9,9 +11,15 @@ import (
 	"github.com/l2cup/kids1/pkg/crawler/file"
 	"github.com/l2cup/kids1/pkg/crawler/web"
 	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/index"
 	"github.com/l2cup/kids1/pkg/log"
+	"github.com/l2cup/kids1/pkg/pool"
 	"github.com/l2cup/kids1/pkg/result"
 	"github.com/l2cup/kids1/pkg/runner"
+	"github.com/l2cup/kids1/pkg/sketch"
+	"github.com/l2cup/kids1/pkg/text"
+	"github.com/l2cup/kids1/pkg/text/stem"
+	"github.com/l2cup/kids1/pkg/text/stopwords"
 )
 
 const (
@@ -32,6 +40,7 @@ type App struct {
 	Configuration    *config.SystemConfig
 	Dispatcher       *dispatcher.Dispatcher
 	ResultRetriever  result.Retriever
+	Index            index.Index
 	DirectoryCrawler crawler.DirCrawler
 	FileCrawler      crawler.FileCrawler
 	WebCrawler       crawler.WebCrawler
@@ -72,7 +81,70 @@ func New() *App {
 		runners:       make([]runner.Runner, 0),
 	}
 
-	app.ResultRetriever = result.NewRetrieverImplementation(50, logger, app)
+	app.ResultRetriever = result.NewRetrieverImplementation(&result.Config{
+		Logger:            logger,
+		RunnerRegistrator: app,
+		BufferSize:        50,
+		PoolSize:          syscfg.RetrieverPoolSize,
+		JobTimeoutMS:      syscfg.RetrieverJobTimeoutMS,
+		Tuner:             app.tunerConfig("retriever"),
+		Vocabulary: &sketch.VocabularyConfig{
+			ExactLimit: syscfg.DiscoverExactLimit,
+			TopK:       syscfg.DiscoverTopK,
+			Width:      syscfg.CountMinWidth,
+			Depth:      syscfg.CountMinDepth,
+		},
+	})
+
+	stopWords, err := stopwords.Load(syscfg.StopWords, syscfg.StopWordsFile)
+	if err != nil {
+		logger.Fatal("[syscfg]couldn't load stop words", "err", err)
+	}
+
+	counterConfig := &text.CounterConfig{
+		Keywords:       syscfg.Keywords,
+		Context:        syscfg.ContextMode,
+		ContextWindow:  syscfg.ContextWindow,
+		MaxMatches:     syscfg.ContextMaxMatches,
+		Discover:       syscfg.DiscoverMode,
+		Words:          syscfg.IndexMode,
+		StopWords:      stopWords,
+		DetectLanguage: syscfg.LanguageDetection,
+		Transliterate:  syscfg.Transliterate,
+	}
+
+	if syscfg.IndexMode {
+		app.Index = index.New(&index.Config{
+			Logger:            logger,
+			RunnerRegistrator: app,
+			Path:              syscfg.IndexPath,
+			FlushIntervalMS:   syscfg.IndexFlushIntervalMS,
+		})
+	}
+
+	if syscfg.AnalysisMode {
+		counterConfig.Analysis = &text.AnalyzerConfig{
+			NGramSizes: []int{2, 3},
+			SketchSize: syscfg.NGramSketchSize,
+			Window:     syscfg.CooccurrenceWindow,
+		}
+	}
+
+	if _, err := stem.New(syscfg.Stemmer); err != nil {
+		logger.Fatal("[syscfg]invalid stemmer", "err", err)
+	}
+	for corpusName, stemmer := range syscfg.Stemmers {
+		if _, err := stem.New(stemmer); err != nil {
+			logger.Fatal("[syscfg]invalid corpus stemmer", "err", err, "corpus_name", corpusName)
+		}
+	}
+
+	counters := crawler.NewCounterFactory(&crawler.CounterFactoryConfig{
+		Counter:        counterConfig,
+		DefaultStemmer: syscfg.Stemmer,
+		Stemmers:       syscfg.Stemmers,
+		KeywordSets:    syscfg.KeywordSets,
+	})
 
 	app.DirectoryCrawler = dir.NewCrawlerImplementation(&dir.Config{
 		Crawler:           crawler.New(logger),
@@ -86,24 +158,116 @@ func New() *App {
 		Crawler:              crawler.New(logger),
 		Dispatcher:           dispatcher,
 		ResultRetriever:      app.ResultRetriever,
-		Keywords:             syscfg.Keywords,
+		Index:                app.Index,
+		Counters:             counters,
 		QueuedFilesSizeLimit: syscfg.FileScanningSizeLimit,
+		PoolSize:             syscfg.FileCrawlerPoolSize,
+		JobTimeoutMS:         syscfg.FileJobTimeoutMS,
+		Tuner:                app.tunerConfig("file"),
 		RunnerRegistrator:    app,
 	})
 
+	var webCache *web.CacheConfig
+	if syscfg.WebCache {
+		webCache = &web.CacheConfig{
+			Path:            syscfg.WebCachePath,
+			FlushIntervalMS: syscfg.WebCacheFlushIntervalMS,
+		}
+	}
+
+	webAuth := make(map[string]*web.AuthProfile, len(syscfg.AuthProfiles))
+	for name, profile := range syscfg.AuthProfiles {
+		webAuth[name] = &web.AuthProfile{
+			Hosts:         profile.Hosts,
+			Headers:       profile.Headers,
+			BearerToken:   profile.BearerToken,
+			BasicUser:     profile.BasicUser,
+			BasicPassword: profile.BasicPassword,
+			CookieFile:    profile.CookieFile,
+		}
+	}
+
 	app.WebCrawler = web.NewCrawlerImplementation(&web.Config{
-		Crawler:           crawler.New(logger),
-		Dispatcher:        dispatcher,
-		ResultRetriever:   app.ResultRetriever,
-		InitialHopCount:   syscfg.HopCount,
-		Keywords:          syscfg.Keywords,
-		TTLMS:             syscfg.URLRefreshTimeMS,
+		Crawler:         crawler.New(logger),
+		Dispatcher:      dispatcher,
+		ResultRetriever: app.ResultRetriever,
+		Index:           app.Index,
+		InitialHopCount: syscfg.HopCount,
+		Counters:        counters,
+		TTLMS:           syscfg.URLRefreshTimeMS,
+		PoolSize:        syscfg.WebCrawlerPoolSize,
+		JobTimeoutMS:    syscfg.WebJobTimeoutMS,
+		Tuner:           app.tunerConfig("web"),
+		Hosts: &web.HostsConfig{
+			UserAgent:            syscfg.UserAgent,
+			IgnoreRobotsTxt:      syscfg.IgnoreRobotsTxt,
+			IgnoreRobotsTxtHosts: syscfg.IgnoreRobotsTxtHosts,
+			Concurrency:          syscfg.HostConcurrency,
+			Concurrencies:        syscfg.HostConcurrencies,
+			CrawlDelayMS:         syscfg.CrawlDelayMS,
+		},
+		Visited: &web.VisitedConfig{
+			BloomFilter:       syscfg.VisitedBloomFilter,
+			Capacity:          syscfg.VisitedCapacity,
+			FalsePositiveRate: syscfg.VisitedFalsePositiveRate,
+		},
+		Text: &web.TextConfig{
+			Title:           syscfg.WebTextTitle,
+			MetaDescription: syscfg.WebTextMetaDescription,
+			AltText:         syscfg.WebTextAlt,
+		},
+		Content: &web.ContentConfig{
+			MaxSize:      syscfg.WebMaxPageSize,
+			HeadCheck:    syscfg.WebHeadCheck,
+			ContentTypes: syscfg.ContentTypes,
+		},
+		Cache: webCache,
+		Auth:  webAuth,
+		Fetch: &web.FetchConfig{
+			MaxRedirects:     syscfg.WebMaxRedirects,
+			Redirects:        syscfg.WebRedirects,
+			ConnectTimeoutMS: syscfg.WebConnectTimeoutMS,
+			ReadTimeoutMS:    syscfg.WebReadTimeoutMS,
+		},
+		CountErrorPages: syscfg.WebCountErrorPages,
+		Guard: &web.GuardConfig{
+			AllowPrivate: syscfg.WebAllowPrivateAddresses,
+			Allow:        syscfg.WebAllowedAddresses,
+		},
+		Transport: &web.TransportConfig{
+			HTTPProxy:  syscfg.WebHTTPProxy,
+			HTTPSProxy: syscfg.WebHTTPSProxy,
+			NoProxy:    syscfg.WebNoProxy,
+			CABundle:   syscfg.WebCABundle,
+			ClientCert: syscfg.WebClientCert,
+			ClientKey:  syscfg.WebClientKey,
+		},
 		RunnerRegistrator: app,
 	})
 
 	return app
 }
 
+func (a *App) tunerConfig(name string) *pool.TunerConfig {
+	if !a.Configuration.AdaptiveConcurrency {
+		return nil
+	}
+
+	interval, err := time.ParseDuration(fmt.Sprintf("%dms", a.Configuration.AdaptiveIntervalMS))
+	if err != nil {
+		a.Logger.Fatal("couldn't parse adaptive interval duration", "err", err, "duration", a.Configuration.AdaptiveIntervalMS)
+	}
+
+	return &pool.TunerConfig{
+		Logger:            a.Logger,
+		Name:              name,
+		MinSize:           a.Configuration.AdaptiveMinPoolSize,
+		MaxSize:           a.Configuration.AdaptiveMaxPoolSize,
+		Interval:          interval,
+		RunnerRegistrator: a,
+	}
+}
+
 func (a *App) Stop() {
 	for _, r := range a.runners {
 		r.Stop()
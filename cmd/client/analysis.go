@@ -0,0 +1,138 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/l2cup/kids1"
+	"github.com/l2cup/kids1/pkg/color"
+	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/sketch"
+	"github.com/urfave/cli/v2"
+)
+
+func NewNGrams(app *kids1.App) *cli.Command {
+	return &cli.Command{
+		Name:      "ngrams",
+		Usage:     "Prints the most frequent n-grams of a corpus",
+		ArgsUsage: "<corpus> <2|3> [k]",
+		Action: func(c *cli.Context) error {
+			corpusName := c.Args().Get(0)
+			n, err := strconv.Atoi(c.Args().Get(1))
+			if corpusName == "" || err != nil {
+				fmt.Println(color.Red("corpus and n-gram size are required"))
+				return nil
+			}
+
+			k := 20
+			if c.Args().Len() > 2 {
+				k, err = strconv.Atoi(c.Args().Get(2))
+				if err != nil || k <= 0 {
+					fmt.Println(color.Red("k must be a positive integer"))
+					return nil
+				}
+			}
+
+			var items []sketch.Item
+			items, err = app.ResultRetriever.GetNGrams(dispatcher.FileJobType, corpusName, n, k)
+			if err != nil {
+				items, err = app.ResultRetriever.GetNGrams(dispatcher.WebJobType, corpusName, n, k)
+			}
+			if err != nil {
+				fmt.Println(color.Red(err))
+				return nil
+			}
+			if len(items) == 0 {
+				fmt.Println(color.Red("no n-grams recorded, is analysis_mode enabled?"))
+				return nil
+			}
+
+			for i, item := range items {
+				fmt.Printf("%d. %s: %d\n", i+1, fmt.Sprint(color.Info(item.Key)), item.Count)
+			}
+			return nil
+		},
+	}
+}
+
+func NewTopTokens(app *kids1.App) *cli.Command {
+	return &cli.Command{
+		Name:      "top",
+		Usage:     "Prints the most frequent tokens of a corpus counted in discover mode",
+		ArgsUsage: "<corpus> [k]",
+		Action: func(c *cli.Context) error {
+			corpusName := c.Args().Get(0)
+			if corpusName == "" {
+				fmt.Println(color.Red("corpus is required"))
+				return nil
+			}
+
+			k := 20
+			if c.Args().Len() > 1 {
+				var err error
+				k, err = strconv.Atoi(c.Args().Get(1))
+				if err != nil || k <= 0 {
+					fmt.Println(color.Red("k must be a positive integer"))
+					return nil
+				}
+			}
+
+			items, err := app.ResultRetriever.GetTopTokens(dispatcher.FileJobType, corpusName, k)
+			if err != nil {
+				items, err = app.ResultRetriever.GetTopTokens(dispatcher.WebJobType, corpusName, k)
+			}
+			if err != nil {
+				fmt.Println(color.Red(err))
+				return nil
+			}
+			if len(items) == 0 {
+				fmt.Println(color.Red("no tokens recorded, is discover_mode enabled?"))
+				return nil
+			}
+
+			for i, item := range items {
+				fmt.Printf("%d. %s: %d\n", i+1, fmt.Sprint(color.Info(item.Key)), item.Count)
+			}
+			return nil
+		},
+	}
+}
+
+func NewCooccurrence(app *kids1.App) *cli.Command {
+	return &cli.Command{
+		Name:      "cooccurrence",
+		Usage:     "Prints the keyword co-occurrence matrix of a corpus",
+		ArgsUsage: "<corpus>",
+		Action: func(c *cli.Context) error {
+			corpusName := c.Args().Get(0)
+
+			matrix, err := app.ResultRetriever.GetCooccurrence(dispatcher.FileJobType, corpusName)
+			if err != nil {
+				matrix, err = app.ResultRetriever.GetCooccurrence(dispatcher.WebJobType, corpusName)
+			}
+			if err != nil {
+				fmt.Println(color.Red(err))
+				return nil
+			}
+			if len(matrix) == 0 {
+				fmt.Println(color.Red("no co-occurrences recorded, is analysis_mode enabled?"))
+				return nil
+			}
+
+			keywords := make([]string, 0, len(matrix))
+			for keyword := range matrix {
+				keywords = append(keywords, keyword)
+			}
+			sort.Strings(keywords)
+
+			for _, keyword := range keywords {
+				fmt.Printf("[%s]\n", fmt.Sprint(color.Info(keyword)))
+				for other, count := range matrix[keyword] {
+					fmt.Printf("%s: %d\n", fmt.Sprint(color.Purple(other)), count)
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -1,10 +1,126 @@
 package client
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
 	"github.com/l2cup/kids1"
+	"github.com/l2cup/kids1/pkg/color"
+	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/text/stem"
 	"github.com/urfave/cli/v2"
 )
 
+// arguments holds the positional arguments of a command together with the
+// flags written after them, which cli stops parsing at the first positional
+// argument. Those flags are written as --name=value, or --name value for
+// flags the command declares with a value, and -- ends them.
+type arguments struct {
+	c          *cli.Context
+	positional []string
+	flags      map[string][]string
+}
+
+func parseArgs(c *cli.Context) *arguments {
+	a := &arguments{
+		c:          c,
+		positional: make([]string, 0, c.Args().Len()),
+		flags:      make(map[string][]string),
+	}
+
+	args := c.Args().Slice()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			a.positional = append(a.positional, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			a.positional = append(a.positional, arg)
+			continue
+		}
+
+		name, value := strings.TrimLeft(arg, "-"), "true"
+		if eq := strings.Index(name, "="); eq >= 0 {
+			name, value = name[:eq], name[eq+1:]
+		} else if takesValue(c.Command, name) {
+			value = ""
+			if i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+		}
+		a.flags[name] = append(a.flags[name], value)
+	}
+
+	return a
+}
+
+// takesValue reports whether the command declares the flag with a value, as
+// opposed to a boolean one.
+func takesValue(command *cli.Command, name string) bool {
+	if command == nil {
+		return false
+	}
+
+	for _, flag := range command.Flags {
+		for _, n := range flag.Names() {
+			if n == name {
+				_, boolean := flag.(*cli.BoolFlag)
+				return !boolean
+			}
+		}
+	}
+	return false
+}
+
+func (a *arguments) Get(i int) string {
+	if i < len(a.positional) {
+		return a.positional[i]
+	}
+	return ""
+}
+
+func (a *arguments) Len() int {
+	return len(a.positional)
+}
+
+func (a *arguments) Slice() []string {
+	return a.positional
+}
+
+func (a *arguments) Bool(name string) bool {
+	if values, ok := a.flags[name]; ok {
+		b, err := strconv.ParseBool(values[len(values)-1])
+		return err == nil && b
+	}
+	return a.c.Bool(name)
+}
+
+func (a *arguments) String(name string) string {
+	if values, ok := a.flags[name]; ok {
+		return values[len(values)-1]
+	}
+	return a.c.String(name)
+}
+
+func (a *arguments) Int(name string) (int, error) {
+	if values, ok := a.flags[name]; ok {
+		return strconv.Atoi(values[len(values)-1])
+	}
+	return a.c.Int(name), nil
+}
+
+// Strings returns every value of a flag that can be given more than once.
+func (a *arguments) Strings(name string) []string {
+	return append(a.c.StringSlice(name), a.flags[name]...)
+}
+
 func NewSummary(app *kids1.App) *cli.Command {
 	return &cli.Command{
 		Name:  "summary",
@@ -37,3 +153,201 @@ func NewQuery(app *kids1.App) *cli.Command {
 		},
 	}
 }
+
+func NewReport(app *kids1.App) *cli.Command {
+	return &cli.Command{
+		Name:  "report",
+		Usage: "Reports how corpus sources were fetched",
+		Subcommands: []*cli.Command{
+			NewReportWebCorpus(app),
+		},
+	}
+}
+
+func corpusFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "stemmer", Usage: "stemmer used to match keywords in the corpus"},
+		&cli.StringFlag{Name: "keywords", Usage: "name of the keyword set from the config to count"},
+	}
+}
+
+func corpusOptions(app *kids1.App, args *arguments) (*dispatcher.CorpusOptions, error) {
+	options := &dispatcher.CorpusOptions{
+		Stemmer:    args.String("stemmer"),
+		KeywordSet: args.String("keywords"),
+	}
+
+	if _, err := stem.New(options.Stemmer); err != nil {
+		return nil, err
+	}
+
+	if options.KeywordSet != "" {
+		if _, ok := app.Configuration.KeywordSets[options.KeywordSet]; !ok {
+			return nil, errors.Errorf("keyword set %q doesn't exist", options.KeywordSet)
+		}
+	}
+	return options, nil
+}
+
+func webScopeFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{Name: "same-host", Usage: "follows links on the host of the url only"},
+		&cli.BoolFlag{Name: "same-domain", Usage: "follows links on the registrable domain of the url only"},
+		&cli.StringFlag{Name: "path-prefix", Usage: "follows links whose path starts with the prefix only"},
+		&cli.StringSliceFlag{Name: "allow", Usage: "follows links matching one of the regular expressions only"},
+		&cli.StringSliceFlag{Name: "deny", Usage: "doesn't follow links matching the regular expression"},
+		&cli.IntFlag{Name: "max-pages", Usage: "caps the pages fetched for the corpus"},
+	}
+}
+
+// webScope returns nil if no scope flag was given, so the crawl follows
+// every link.
+func webScope(args *arguments) (*dispatcher.WebScope, error) {
+	maxPages, err := args.Int("max-pages")
+	if err != nil || maxPages < 0 {
+		return nil, errors.Errorf("invalid max pages %q", args.String("max-pages"))
+	}
+
+	scope := &dispatcher.WebScope{
+		SameHost:   args.Bool("same-host"),
+		SameDomain: args.Bool("same-domain"),
+		PathPrefix: args.String("path-prefix"),
+		Allow:      args.Strings("allow"),
+		Deny:       args.Strings("deny"),
+		MaxPages:   maxPages,
+	}
+
+	for _, expr := range append(scope.Allow, scope.Deny...) {
+		if _, err := regexp.Compile(expr); err != nil {
+			return nil, errors.Wrapf(err, "invalid expression %q", expr)
+		}
+	}
+
+	if !scope.SameHost && !scope.SameDomain && scope.PathPrefix == "" &&
+		len(scope.Allow) == 0 && len(scope.Deny) == 0 && scope.MaxPages == 0 {
+		return nil, nil
+	}
+	return scope, nil
+}
+
+func webFetchFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{Name: "max-redirects", Usage: "caps the redirects followed per page"},
+		&cli.StringFlag{Name: "redirects", Usage: "where redirects may lead: any, same-host, same-domain or none"},
+		&cli.IntFlag{Name: "connect-timeout", Usage: "milliseconds to wait for a connection to a host"},
+		&cli.IntFlag{Name: "read-timeout", Usage: "milliseconds to wait for a whole response once connected"},
+		&cli.IntFlag{Name: "max-size", Usage: "largest page in bytes that's downloaded"},
+	}
+}
+
+// webFetch returns nil if no fetch flag was given, so the crawl uses the
+// system config.
+func webFetch(args *arguments) (*dispatcher.WebFetch, error) {
+	values := make(map[string]int)
+	for _, name := range []string{"max-redirects", "connect-timeout", "read-timeout", "max-size"} {
+		value, err := args.Int(name)
+		if err != nil || value < 0 {
+			return nil, errors.Errorf("invalid %s %q", name, args.String(name))
+		}
+		values[name] = value
+	}
+
+	fetch := &dispatcher.WebFetch{
+		MaxRedirects:     values["max-redirects"],
+		Redirects:        args.String("redirects"),
+		ConnectTimeoutMS: uint64(values["connect-timeout"]),
+		ReadTimeoutMS:    uint64(values["read-timeout"]),
+		MaxBodySize:      values["max-size"],
+	}
+
+	if fetch.Redirects != "" && !dispatcher.ValidRedirects(fetch.Redirects) {
+		return nil, errors.Errorf("unknown redirect policy %q", fetch.Redirects)
+	}
+
+	if *fetch == (dispatcher.WebFetch{}) {
+		return nil, nil
+	}
+	return fetch, nil
+}
+
+func printKeywordSet(app *kids1.App, jobType dispatcher.JobType, corpusName string) {
+	keywordSet, err := app.ResultRetriever.GetKeywordSet(jobType, corpusName)
+	if err != nil {
+		return
+	}
+	fmt.Printf("keyword set: %s\n", fmt.Sprint(color.Purple(keywordSet)))
+}
+
+func printLanguageResults(app *kids1.App, jobType dispatcher.JobType, corpusName, language string) error {
+	results, err := app.ResultRetriever.GetLanguageSummary(jobType, corpusName, language)
+	if err != nil {
+		fmt.Println(color.Red(err))
+		return nil
+	}
+
+	fmt.Println(color.Yellow("Printing results for corpus: %s\n", corpusName))
+	fmt.Printf("language: %s\n", fmt.Sprint(color.Purple(language)))
+	for k, v := range results {
+		fmt.Printf("%s : %d\n", fmt.Sprint(color.Info(k)), v)
+	}
+	return nil
+}
+
+func NewLanguages(app *kids1.App) *cli.Command {
+	return &cli.Command{
+		Name:      "languages",
+		Usage:     "Prints the number of files or pages per detected language of a corpus",
+		ArgsUsage: "<corpus>",
+		Action: func(c *cli.Context) error {
+			corpusName := c.Args().Get(0)
+
+			totals, _, err := app.ResultRetriever.GetLanguages(dispatcher.FileJobType, corpusName)
+			if err != nil {
+				totals, _, err = app.ResultRetriever.GetLanguages(dispatcher.WebJobType, corpusName)
+			}
+			if err != nil {
+				fmt.Println(color.Red(err))
+				return nil
+			}
+			if len(totals) == 0 {
+				fmt.Println(color.Red("no languages recorded, is language_detection enabled?"))
+				return nil
+			}
+
+			for language, count := range totals {
+				fmt.Printf("%s: %d\n", fmt.Sprint(color.Purple(language)), count)
+			}
+			return nil
+		},
+	}
+}
+
+func printNormalizedSummaries(app *kids1.App, jobType dispatcher.JobType) error {
+	results, err := app.ResultRetriever.GetNormalizedSummaries(jobType)
+	if err != nil {
+		fmt.Println(color.Red(err))
+		return nil
+	}
+	if len(results) == 0 {
+		fmt.Println(color.Red("results for summary do not exist"))
+		return nil
+	}
+
+	for corpusName, summary := range results {
+		fmt.Printf("[%s] keyword set: %s, documents: %d, tokens: %d\n",
+			fmt.Sprint(color.Info(corpusName)), summary.KeywordSet, summary.Documents, summary.Tokens)
+
+		keywords := make([]string, 0, len(summary.Metrics))
+		for k := range summary.Metrics {
+			keywords = append(keywords, k)
+		}
+		sort.Strings(keywords)
+
+		for _, k := range keywords {
+			m := summary.Metrics[k]
+			fmt.Printf("%s: count %d, tf %.6f, per million %.2f, df %.2f, tf-idf %.6f\n",
+				fmt.Sprint(color.Purple(k)), m.Count, m.TermFrequency, m.PerMillion, m.DocumentFrequency, m.TFIDF)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func newTestContext(t *testing.T, args ...string) *cli.Context {
+	command := &cli.Command{Flags: []cli.Flag{
+		&cli.StringFlag{Name: "corpus"},
+		&cli.IntFlag{Name: "limit", Value: 20},
+		&cli.BoolFlag{Name: "verbose"},
+		&cli.StringSliceFlag{Name: "allow"},
+	}}
+
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range command.Flags {
+		assert.NoError(t, f.Apply(set))
+	}
+	assert.NoError(t, set.Parse(args))
+
+	c := cli.NewContext(cli.NewApp(), set, nil)
+	c.Command = command
+	return c
+}
+
+func TestParseArgs(t *testing.T) {
+	args := parseArgs(newTestContext(t, "--corpus=a", "brown", "fox", "--limit=5", "--verbose", "-", "--corpus=b"))
+
+	assert.Equal(t, 3, args.Len())
+	assert.Equal(t, []string{"brown", "fox", "-"}, args.Slice())
+	assert.Equal(t, "fox", args.Get(1))
+	assert.Equal(t, "", args.Get(3))
+
+	// flags written after the positional arguments override the parsed ones.
+	assert.Equal(t, "b", args.String("corpus"))
+	limit, err := args.Int("limit")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, limit)
+	assert.True(t, args.Bool("verbose"))
+
+	args = parseArgs(newTestContext(t, "--limit=7", "query", "--limit=x"))
+	_, err = args.Int("limit")
+	assert.Error(t, err)
+
+	args = parseArgs(newTestContext(t, "--limit=7", "query"))
+	limit, err = args.Int("limit")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, limit)
+	assert.Equal(t, "", args.String("corpus"))
+	assert.False(t, args.Bool("verbose"))
+}
+
+func TestParseArgsSeparateValues(t *testing.T) {
+	args := parseArgs(newTestContext(t, "query", "--limit", "5", "--verbose", "more", "--corpus", "a", "--allow", "x", "--allow=y"))
+
+	assert.Equal(t, []string{"query", "more"}, args.Slice())
+	limit, err := args.Int("limit")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, limit)
+	assert.True(t, args.Bool("verbose"))
+	assert.Equal(t, "a", args.String("corpus"))
+	assert.Equal(t, []string{"x", "y"}, args.Strings("allow"))
+
+	// a value flag missing its value is invalid rather than true.
+	args = parseArgs(newTestContext(t, "query", "--limit"))
+	_, err = args.Int("limit")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"query"}, args.Slice())
+
+	args = parseArgs(newTestContext(t, "query", "--", "--limit", "5", "-x"))
+	assert.Equal(t, []string{"query", "--limit", "5", "-x"}, args.Slice())
+	limit, err = args.Int("limit")
+	assert.NoError(t, err)
+	assert.Equal(t, 20, limit)
+}
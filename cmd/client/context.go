@@ -0,0 +1,54 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/l2cup/kids1"
+	"github.com/l2cup/kids1/pkg/color"
+	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/text"
+	"github.com/urfave/cli/v2"
+)
+
+func NewContext(app *kids1.App) *cli.Command {
+	return &cli.Command{
+		Name:      "context",
+		Usage:     "Prints keyword in context snippets for a corpus",
+		ArgsUsage: "<corpus> <keyword>",
+		Action: func(c *cli.Context) error {
+			corpusName, keyword := c.Args().Get(0), c.Args().Get(1)
+			if corpusName == "" || keyword == "" {
+				fmt.Println(color.Red("corpus and keyword are required"))
+				return nil
+			}
+
+			matches, err := app.ResultRetriever.GetMatches(dispatcher.FileJobType, corpusName, keyword)
+			if err != nil {
+				matches, err = app.ResultRetriever.GetMatches(dispatcher.WebJobType, corpusName, keyword)
+			}
+			if err != nil {
+				fmt.Println(color.Red(err))
+				return nil
+			}
+			if len(matches) == 0 {
+				fmt.Println(color.Red("no matches recorded, is context_mode enabled?"))
+				return nil
+			}
+
+			for _, m := range matches {
+				fmt.Printf("%s:%s: %s\n",
+					fmt.Sprint(color.Info(m.Source)),
+					fmt.Sprint(color.Purple(m.Line)),
+					highlight(m),
+				)
+			}
+			return nil
+		},
+	}
+}
+
+func highlight(m text.Match) string {
+	return m.Snippet[:m.KeywordStart] +
+		color.Yellow(m.Snippet[m.KeywordStart:m.KeywordEnd]) +
+		m.Snippet[m.KeywordEnd:]
+}
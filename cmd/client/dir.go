@@ -2,6 +2,8 @@ package client
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 
 	"github.com/l2cup/kids1"
 	"github.com/l2cup/kids1/pkg/color"
@@ -11,10 +13,19 @@ import (
 
 func NewAddDir(app *kids1.App) *cli.Command {
 	return &cli.Command{
-		Name:  "ad",
-		Usage: "Adds the directory to the crawler",
+		Name:      "ad",
+		Usage:     "Adds the directory to the crawler",
+		ArgsUsage: "[--stemmer <english|serbian|none>] [--keywords <set>] <path>",
+		Flags:     corpusFlags(),
 		Action: func(c *cli.Context) error {
-			cErr := app.DirectoryCrawler.AddDirectoryPath(c.Args().Get(0))
+			args := parseArgs(c)
+			options, err := corpusOptions(app, args)
+			if err != nil {
+				fmt.Println(color.Red(err))
+				return nil
+			}
+
+			cErr := app.DirectoryCrawler.AddDirectoryPath(args.Get(0), options)
 			if cErr.IsNotNil() {
 				fmt.Println(color.Red(cErr.Message))
 				return nil
@@ -26,9 +37,17 @@ func NewAddDir(app *kids1.App) *cli.Command {
 
 func NewGetFileSummary(app *kids1.App) *cli.Command {
 	return &cli.Command{
-		Name:  "file",
-		Usage: "Gets file summaries",
+		Name:      "file",
+		Usage:     "Gets file summaries",
+		ArgsUsage: "[--normalized]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "normalized", Usage: "prints term frequency, tf-idf and per-million rates"},
+		},
 		Action: func(c *cli.Context) error {
+			if parseArgs(c).Bool("normalized") {
+				return printNormalizedSummaries(app, dispatcher.FileJobType)
+			}
+
 			results, err := app.ResultRetriever.GetSummaries(dispatcher.FileJobType)
 			if err != nil {
 				fmt.Println(color.Red(err))
@@ -75,10 +94,23 @@ func NewQueryFileCorpus(app *kids1.App) *cli.Command {
 
 func NewGetFileCorpus(app *kids1.App) *cli.Command {
 	return &cli.Command{
-		Name:  "file",
-		Usage: "Gets file corpuses",
+		Name:      "file",
+		Usage:     "Gets file corpuses",
+		ArgsUsage: "<corpus> [--by-file] [--lang=<language>]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "by-file", Usage: "breaks the results down per file"},
+			&cli.StringFlag{Name: "lang", Usage: "counts only files detected as the language, e.g. en or sr"},
+		},
 		Action: func(c *cli.Context) error {
-			results, err := app.ResultRetriever.GetSummary(dispatcher.FileJobType, c.Args().Get(0))
+			args := parseArgs(c)
+			if args.Bool("by-file") {
+				return printSourceResults(app, dispatcher.FileJobType, args.Get(0))
+			}
+			if language := args.String("lang"); language != "" {
+				return printLanguageResults(app, dispatcher.FileJobType, args.Get(0), language)
+			}
+
+			results, err := app.ResultRetriever.GetSummary(dispatcher.FileJobType, args.Get(0))
 			if err != nil {
 				fmt.Println(color.Red(err))
 				return nil
@@ -87,7 +119,8 @@ func NewGetFileCorpus(app *kids1.App) *cli.Command {
 				fmt.Println(color.Red("results for summary do not exist"))
 				return nil
 			}
-			fmt.Println(color.Yellow("Printing results for corpus: %s\n", c.Args().Get(0)))
+			fmt.Println(color.Yellow("Printing results for corpus: %s\n", args.Get(0)))
+			printKeywordSet(app, dispatcher.FileJobType, args.Get(0))
 			for k, v := range results {
 				fmt.Printf("%s : %d\n", fmt.Sprint(color.Info(k)), v)
 			}
@@ -96,12 +129,95 @@ func NewGetFileCorpus(app *kids1.App) *cli.Command {
 	}
 }
 
+func NewTopFiles(app *kids1.App) *cli.Command {
+	return &cli.Command{
+		Name:      "top-files",
+		Usage:     "Ranks files of a corpus by keyword frequency",
+		ArgsUsage: "<corpus> <keyword> [n]",
+		Action: func(c *cli.Context) error {
+			return printTopSources(app, parseArgs(c))
+		},
+	}
+}
+
+func printSourceResults(app *kids1.App, jobType dispatcher.JobType, corpusName string) error {
+	results, err := app.ResultRetriever.GetSourceSummary(jobType, corpusName)
+	if err != nil {
+		fmt.Println(color.Red(err))
+		return nil
+	}
+	if len(results) == 0 {
+		fmt.Println(color.Red("results for summary do not exist"))
+		return nil
+	}
+
+	sources := make([]string, 0, len(results))
+	for source := range results {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	_, languages, _ := app.ResultRetriever.GetLanguages(jobType, corpusName)
+	finalURLs, _ := app.ResultRetriever.GetFinalURLs(jobType, corpusName)
+
+	fmt.Println(color.Yellow("Printing results for corpus: %s\n", corpusName))
+	for _, source := range sources {
+		if language, ok := languages[source]; ok {
+			fmt.Printf("[%s] (%s)\n", fmt.Sprint(color.Info(source)), language)
+		} else {
+			fmt.Printf("[%s]\n", fmt.Sprint(color.Info(source)))
+		}
+		if finalURL, ok := finalURLs[source]; ok {
+			fmt.Printf("redirected to: %s\n", finalURL)
+		}
+		for k, v := range results[source] {
+			fmt.Printf("%s: %d\n", fmt.Sprint(color.Purple(k)), v)
+		}
+	}
+	return nil
+}
+
+func printTopSources(app *kids1.App, args *arguments) error {
+	if args.Len() < 2 {
+		fmt.Println(color.Red("corpus and keyword are required"))
+		return nil
+	}
+
+	n := 10
+	if args.Len() > 2 {
+		parsed, err := strconv.Atoi(args.Get(2))
+		if err != nil || parsed <= 0 {
+			fmt.Println(color.Red("n must be a positive integer"))
+			return nil
+		}
+		n = parsed
+	}
+
+	top, err := app.ResultRetriever.TopSources(dispatcher.FileJobType, args.Get(0), args.Get(1), n)
+	if err != nil {
+		fmt.Println(color.Red(err))
+		return nil
+	}
+	if len(top) == 0 {
+		fmt.Println(color.Red(fmt.Sprintf("no files in %s contain %s", args.Get(0), args.Get(1))))
+		return nil
+	}
+
+	for i, sc := range top {
+		fmt.Printf("%d. %s: %d\n", i+1, fmt.Sprint(color.Info(sc.Source)), sc.Count)
+	}
+	return nil
+}
+
 func NewCFS(app *kids1.App) *cli.Command {
 	return &cli.Command{
 		Name:  "cfs",
-		Usage: "clears file summary",
+		Usage: "clears file summary and its indexed documents",
 		Action: func(c *cli.Context) error {
 			app.ResultRetriever.DeleteSummary(dispatcher.FileJobType)
+			if app.Index != nil {
+				app.Index.RemoveCorpus(dispatcher.FileJobType, "")
+			}
 			return nil
 		},
 	}
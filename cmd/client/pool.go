@@ -0,0 +1,56 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/l2cup/kids1"
+	"github.com/l2cup/kids1/pkg/color"
+	"github.com/l2cup/kids1/pkg/pool"
+	"github.com/urfave/cli/v2"
+)
+
+func NewPool(app *kids1.App) *cli.Command {
+	return &cli.Command{
+		Name:      "pool",
+		Usage:     "Shows or resizes worker pools",
+		ArgsUsage: "[file|web|retriever] [size]",
+		Action: func(c *cli.Context) error {
+			pools := map[string]pool.Resizer{
+				"file":      app.FileCrawler,
+				"web":       app.WebCrawler,
+				"retriever": app.ResultRetriever,
+			}
+
+			if c.Args().Len() == 0 {
+				for _, name := range []string{"file", "web", "retriever"} {
+					fmt.Printf("%s: %d\n", fmt.Sprint(color.Purple(name)), pools[name].PoolSize())
+				}
+				return nil
+			}
+
+			resizer, ok := pools[c.Args().Get(0)]
+			if !ok {
+				fmt.Println(color.Red(fmt.Sprintf("no pool named '%s'", c.Args().Get(0))))
+				return nil
+			}
+
+			if c.Args().Len() == 1 {
+				fmt.Printf("%s: %d\n", fmt.Sprint(color.Purple(c.Args().Get(0))), resizer.PoolSize())
+				return nil
+			}
+
+			size, err := strconv.Atoi(c.Args().Get(1))
+			// a pool without workers would drop every job it's given and leave
+			// their summaries waiting forever.
+			if err != nil || size < 1 {
+				fmt.Println(color.Red("pool size must be a positive integer"))
+				return nil
+			}
+
+			resizer.SetPoolSize(size)
+			fmt.Println(color.Info(fmt.Sprintf("resized %s pool to %d", c.Args().Get(0), size)))
+			return nil
+		},
+	}
+}
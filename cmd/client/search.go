@@ -0,0 +1,52 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/l2cup/kids1"
+	"github.com/l2cup/kids1/pkg/color"
+	"github.com/urfave/cli/v2"
+)
+
+func NewSearch(app *kids1.App) *cli.Command {
+	return &cli.Command{
+		Name:      "search",
+		Usage:     "Searches indexed corpuses, supports AND, OR, NOT, parentheses and \"phrases\"",
+		ArgsUsage: "[--corpus <corpus>] [--limit <n>] <query>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "corpus", Usage: "limits results to a single corpus"},
+			&cli.IntFlag{Name: "limit", Value: 20, Usage: "maximum number of results"},
+		},
+		Action: func(c *cli.Context) error {
+			if app.Index == nil {
+				fmt.Println(color.Red("index is disabled, set index_mode in the config"))
+				return nil
+			}
+
+			args := parseArgs(c)
+			limit, err := args.Int("limit")
+			if err != nil || limit < 0 {
+				fmt.Println(color.Red(fmt.Sprintf("invalid limit %q", args.String("limit"))))
+				return nil
+			}
+
+			query := strings.Join(args.Slice(), " ")
+			hits, err := app.Index.Search(query, args.String("corpus"), limit)
+			if err != nil {
+				fmt.Println(color.Red(err))
+				return nil
+			}
+			if len(hits) == 0 {
+				fmt.Println(color.Red("no results"))
+				return nil
+			}
+
+			for i, hit := range hits {
+				fmt.Printf("%d. %s [%s] %.4f\n",
+					i+1, fmt.Sprint(color.Info(hit.Source)), fmt.Sprint(color.Purple(hit.CorpusName)), hit.Score)
+			}
+			return nil
+		},
+	}
+}
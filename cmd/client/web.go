@@ -2,10 +2,14 @@ package client
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/l2cup/kids1"
 	"github.com/l2cup/kids1/pkg/color"
+	"github.com/l2cup/kids1/pkg/crawler"
 	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/result"
 	"github.com/urfave/cli/v2"
 )
 
@@ -13,8 +17,44 @@ func NewAddWeb(app *kids1.App) *cli.Command {
 	return &cli.Command{
 		Name:  "aw",
 		Usage: "Adds the url to the crawler",
+		ArgsUsage: "[--stemmer <english|serbian|none>] [--keywords <set>] [--same-host] [--same-domain] " +
+			"[--path-prefix <prefix>] [--allow <regexp>]... [--deny <regexp>]... [--max-pages <n>] " +
+			"[--max-redirects <n>] [--redirects <any|same-host|same-domain|none>] [--connect-timeout <ms>] " +
+			"[--read-timeout <ms>] [--max-size <bytes>] [--sitemap | --feed] <url>",
+		Flags: append(append(append(corpusFlags(), webScopeFlags()...), webFetchFlags()...),
+			&cli.BoolFlag{Name: "sitemap", Usage: "crawls the pages listed by the sitemap or sitemap index at the url"},
+			&cli.BoolFlag{Name: "feed", Usage: "crawls the pages listed by the rss or atom feed at the url"},
+		),
 		Action: func(c *cli.Context) error {
-			app.WebCrawler.AddWebPage(c.Args().Get(0))
+			args := parseArgs(c)
+			options, err := corpusOptions(app, args)
+			if err != nil {
+				fmt.Println(color.Red(err))
+				return nil
+			}
+
+			options.Scope, err = webScope(args)
+			if err != nil {
+				fmt.Println(color.Red(err))
+				return nil
+			}
+
+			options.Fetch, err = webFetch(args)
+			if err != nil {
+				fmt.Println(color.Red(err))
+				return nil
+			}
+
+			switch {
+			case args.Bool("sitemap") && args.Bool("feed"):
+				fmt.Println(color.Red("--sitemap and --feed can't be used together"))
+			case args.Bool("sitemap"):
+				app.WebCrawler.AddSeedList(args.Get(0), crawler.SitemapSeedList, options)
+			case args.Bool("feed"):
+				app.WebCrawler.AddSeedList(args.Get(0), crawler.FeedSeedList, options)
+			default:
+				app.WebCrawler.AddWebPage(args.Get(0), options)
+			}
 			return nil
 		},
 	}
@@ -22,9 +62,22 @@ func NewAddWeb(app *kids1.App) *cli.Command {
 
 func NewGetWebSummary(app *kids1.App) *cli.Command {
 	return &cli.Command{
-		Name:  "web",
-		Usage: "Gets web summaries",
+		Name:      "web",
+		Usage:     "Gets web summaries",
+		ArgsUsage: "[--normalized] [--by-domain]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{Name: "normalized", Usage: "prints term frequency, tf-idf and per-million rates"},
+			&cli.BoolFlag{Name: "by-domain", Usage: "groups the results by the host pages were fetched from"},
+		},
 		Action: func(c *cli.Context) error {
+			args := parseArgs(c)
+			if args.Bool("normalized") {
+				return printNormalizedSummaries(app, dispatcher.WebJobType)
+			}
+			if args.Bool("by-domain") {
+				return printDomainSummaries(app)
+			}
+
 			results, err := app.ResultRetriever.GetSummaries(dispatcher.WebJobType)
 			if err != nil {
 				fmt.Println(color.Red(err))
@@ -71,10 +124,27 @@ func NewQueryWebCorpus(app *kids1.App) *cli.Command {
 
 func NewGetWebCorpus(app *kids1.App) *cli.Command {
 	return &cli.Command{
-		Name:  "web",
-		Usage: "Gets web corpuses",
+		Name:      "web",
+		Usage:     "Gets web corpuses",
+		ArgsUsage: "<corpus> [--lang=<language>] [--by-url] | --domain=<host> [corpus]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "lang", Usage: "counts only pages detected as the language, e.g. en or sr"},
+			&cli.BoolFlag{Name: "by-url", Usage: "breaks the results down per page"},
+			&cli.StringFlag{Name: "domain", Usage: "prints the pages fetched from the host and its subdomains"},
+		},
 		Action: func(c *cli.Context) error {
-			results, err := app.ResultRetriever.GetSummary(dispatcher.WebJobType, c.Args().Get(0))
+			args := parseArgs(c)
+			if domain := args.String("domain"); domain != "" {
+				return printDomainSources(app, args.Get(0), domain)
+			}
+			if args.Bool("by-url") {
+				return printSourceResults(app, dispatcher.WebJobType, args.Get(0))
+			}
+			if language := args.String("lang"); language != "" {
+				return printLanguageResults(app, dispatcher.WebJobType, args.Get(0), language)
+			}
+
+			results, err := app.ResultRetriever.GetSummary(dispatcher.WebJobType, args.Get(0))
 			if err != nil {
 				fmt.Println(color.Red(err))
 				return nil
@@ -83,21 +153,193 @@ func NewGetWebCorpus(app *kids1.App) *cli.Command {
 				fmt.Println(color.Red("results for summary do not exist"))
 				return nil
 			}
-			fmt.Println(color.Yellow("Printing results for corpus: %s\n", c.Args().Get(0)))
+			fmt.Println(color.Yellow("Printing results for corpus: %s\n", args.Get(0)))
+			printKeywordSet(app, dispatcher.WebJobType, args.Get(0))
 			for k, v := range results {
 				fmt.Printf("%s : %d\n", fmt.Sprint(color.Info(k)), v)
 			}
+			printBlocked(app, args.Get(0))
 			return nil
 		},
 	}
 }
 
+func NewReportWebCorpus(app *kids1.App) *cli.Command {
+	return &cli.Command{
+		Name:      "web",
+		Usage:     "Lists the failed and slow pages of a web corpus",
+		ArgsUsage: "<corpus> [--slow=<ms>]",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "slow", Value: 1000, Usage: "milliseconds after which a page is listed as slow"},
+		},
+		Action: func(c *cli.Context) error {
+			args := parseArgs(c)
+			slow, err := args.Int("slow")
+			if err != nil || slow < 0 {
+				fmt.Println(color.Red(fmt.Sprintf("invalid slow threshold %q", args.String("slow"))))
+				return nil
+			}
+			return printFetchReport(app, args.Get(0), time.Duration(slow)*time.Millisecond)
+		},
+	}
+}
+
+func printFetchReport(app *kids1.App, corpusName string, slowAfter time.Duration) error {
+	fetches, err := app.ResultRetriever.GetFetches(dispatcher.WebJobType, corpusName)
+	if err != nil {
+		fmt.Println(color.Red(err))
+		return nil
+	}
+	if len(fetches) == 0 {
+		fmt.Println(color.Red("no pages were fetched for the corpus"))
+		return nil
+	}
+
+	var failed, skipped, slow []*result.Fetch
+	var bytes int64
+	for _, fetch := range fetches {
+		bytes += fetch.Bytes
+		if fetch.Skipped != "" {
+			skipped = append(skipped, fetch)
+		} else if fetch.Failed() {
+			failed = append(failed, fetch)
+		} else if fetch.Latency >= slowAfter {
+			slow = append(slow, fetch)
+		}
+	}
+	sort.Slice(failed, func(i, j int) bool { return failed[i].URL < failed[j].URL })
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i].URL < skipped[j].URL })
+	sort.Slice(slow, func(i, j int) bool { return slow[i].Latency > slow[j].Latency })
+
+	fmt.Println(color.Yellow("Printing fetch report for corpus: %s\n", corpusName))
+	fmt.Printf("fetched: %d, failed: %d, skipped: %d, slow: %d, bytes: %d\n",
+		len(fetches), len(failed), len(skipped), len(slow), bytes)
+
+	if len(failed) > 0 {
+		fmt.Println(color.Yellow("\nFailed pages:"))
+		for _, fetch := range failed {
+			reason := fetch.Error
+			if reason == "" {
+				reason = fmt.Sprintf("status %d", fetch.Status)
+			}
+			fmt.Printf("[%s] %s\n", fmt.Sprint(color.Red(fetch.URL)), reason)
+			printFetch(fetch)
+		}
+	}
+
+	if len(skipped) > 0 {
+		fmt.Println(color.Yellow("\nSkipped pages:"))
+		for _, fetch := range skipped {
+			fmt.Printf("[%s] %s\n", fmt.Sprint(color.Purple(fetch.URL)), fetch.Skipped)
+			printFetch(fetch)
+		}
+	}
+
+	if len(slow) > 0 {
+		fmt.Println(color.Yellow("\nSlow pages:"))
+		for _, fetch := range slow {
+			fmt.Printf("[%s] %s\n", fmt.Sprint(color.Info(fetch.URL)), fetch.Latency.Round(time.Millisecond))
+			printFetch(fetch)
+		}
+	}
+	return nil
+}
+
+func printFetch(fetch *result.Fetch) {
+	fmt.Printf("status: %d, bytes: %d, latency: %s, depth: %d\n",
+		fetch.Status, fetch.Bytes, fetch.Latency.Round(time.Millisecond), fetch.Depth)
+	if fetch.Parent != "" {
+		fmt.Printf("found on: %s\n", fetch.Parent)
+	}
+}
+
+func printBlocked(app *kids1.App, corpusName string) {
+	blocked, err := app.ResultRetriever.GetBlocked(dispatcher.WebJobType, corpusName)
+	if err != nil || len(blocked) == 0 {
+		return
+	}
+
+	urls := make([]string, 0, len(blocked))
+	for url := range blocked {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+
+	fmt.Println(color.Yellow("\nBlocked urls:"))
+	for _, url := range urls {
+		fmt.Printf("%s : %s\n", fmt.Sprint(color.Red(url)), blocked[url])
+	}
+}
+
+func printDomainSummaries(app *kids1.App) error {
+	results, err := app.ResultRetriever.GetDomainSummaries(dispatcher.WebJobType)
+	if err != nil {
+		fmt.Println(color.Red(err))
+		return nil
+	}
+	if len(results) == 0 {
+		fmt.Println(color.Red("results for summary do not exist"))
+		return nil
+	}
+
+	domains := make([]string, 0, len(results))
+	for domain := range results {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	for _, domain := range domains {
+		fmt.Printf("[%s]\n", fmt.Sprint(color.Info(domain)))
+		for k, v := range results[domain] {
+			fmt.Printf("%s: %d\n", fmt.Sprint(color.Purple(k)), v)
+		}
+	}
+	return nil
+}
+
+func printDomainSources(app *kids1.App, corpusName, domain string) error {
+	results, err := app.ResultRetriever.GetDomainSources(dispatcher.WebJobType, corpusName, domain)
+	if err != nil {
+		fmt.Println(color.Red(err))
+		return nil
+	}
+	if len(results) == 0 {
+		fmt.Println(color.Red("no pages were fetched from the domain"))
+		return nil
+	}
+
+	sources := make([]string, 0, len(results))
+	totals := make(map[string]int64)
+	for source, sourceResults := range results {
+		sources = append(sources, source)
+		for k, v := range sourceResults {
+			totals[k] += v
+		}
+	}
+	sort.Strings(sources)
+
+	fmt.Println(color.Yellow("Printing results for domain: %s\n", domain))
+	for k, v := range totals {
+		fmt.Printf("%s : %d\n", fmt.Sprint(color.Info(k)), v)
+	}
+	for _, source := range sources {
+		fmt.Printf("[%s]\n", fmt.Sprint(color.Info(source)))
+		for k, v := range results[source] {
+			fmt.Printf("%s: %d\n", fmt.Sprint(color.Purple(k)), v)
+		}
+	}
+	return nil
+}
+
 func NewCWS(app *kids1.App) *cli.Command {
 	return &cli.Command{
 		Name:  "cws",
-		Usage: "clears web summary",
+		Usage: "clears web summary and its indexed documents",
 		Action: func(c *cli.Context) error {
 			app.ResultRetriever.DeleteSummary(dispatcher.WebJobType)
+			if app.Index != nil {
+				app.Index.RemoveCorpus(dispatcher.WebJobType, "")
+			}
 			return nil
 		},
 	}
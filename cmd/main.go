@@ -44,9 +44,18 @@ func createCmd(app *kids1.App) *cli.App {
 		client.NewAddWeb(app),
 		client.NewGet(app),
 		client.NewQuery(app),
+		client.NewTopFiles(app),
+		client.NewContext(app),
+		client.NewNGrams(app),
+		client.NewCooccurrence(app),
+		client.NewTopTokens(app),
+		client.NewSearch(app),
+		client.NewLanguages(app),
 		client.NewSummary(app),
+		client.NewReport(app),
 		client.NewCFS(app),
 		client.NewCWS(app),
+		client.NewPool(app),
 	}
 
 	return cmd
@@ -3,9 +3,12 @@ package config
 import (
 	"bufio"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -23,6 +26,347 @@ type SystemConfig struct {
 	FileScanningSizeLimit uint64   `properties:"file_scanning_size_limit" json:"file_scanning_size_limit"`
 	HopCount              int      `properties:"hop_count" json:"hop_count"`
 	Keywords              []string `json:"keywords"`
+
+	FileCrawlerPoolSize   int    `properties:"file_crawler_pool_size" json:"file_crawler_pool_size"`
+	WebCrawlerPoolSize    int    `properties:"web_crawler_pool_size" json:"web_crawler_pool_size"`
+	RetrieverPoolSize     int    `properties:"retriever_pool_size" json:"retriever_pool_size"`
+	FileJobTimeoutMS      uint64 `properties:"file_job_timeout" json:"file_job_timeout"`
+	WebJobTimeoutMS       uint64 `properties:"web_job_timeout" json:"web_job_timeout"`
+	RetrieverJobTimeoutMS uint64 `properties:"retriever_job_timeout" json:"retriever_job_timeout"`
+
+	AdaptiveConcurrency bool   `properties:"adaptive_concurrency" json:"adaptive_concurrency"`
+	AdaptiveMinPoolSize int    `properties:"adaptive_min_pool_size" json:"adaptive_min_pool_size"`
+	AdaptiveMaxPoolSize int    `properties:"adaptive_max_pool_size" json:"adaptive_max_pool_size"`
+	AdaptiveIntervalMS  uint64 `properties:"adaptive_interval" json:"adaptive_interval"`
+
+	ContextMode       bool `properties:"context_mode" json:"context_mode"`
+	ContextWindow     int  `properties:"context_window" json:"context_window"`
+	ContextMaxMatches int  `properties:"context_max_matches" json:"context_max_matches"`
+
+	AnalysisMode       bool `properties:"analysis_mode" json:"analysis_mode"`
+	NGramSketchSize    int  `properties:"ngram_sketch_size" json:"ngram_sketch_size"`
+	CooccurrenceWindow int  `properties:"cooccurrence_window" json:"cooccurrence_window"`
+
+	DiscoverMode       bool `properties:"discover_mode" json:"discover_mode"`
+	DiscoverExactLimit int  `properties:"discover_exact_limit" json:"discover_exact_limit"`
+	DiscoverTopK       int  `properties:"discover_top_k" json:"discover_top_k"`
+	CountMinWidth      int  `properties:"countmin_width" json:"countmin_width"`
+	CountMinDepth      int  `properties:"countmin_depth" json:"countmin_depth"`
+
+	IndexMode            bool   `properties:"index_mode" json:"index_mode"`
+	IndexPath            string `properties:"index_path" json:"index_path"`
+	IndexFlushIntervalMS uint64 `properties:"index_flush_interval" json:"index_flush_interval"`
+
+	Stemmer string `properties:"stemmer" json:"stemmer"`
+	// Stemmers maps corpus names to stemmers, given as stemmer.<corpus> in
+	// properties files.
+	Stemmers map[string]string `properties:"-" json:"stemmers"`
+
+	// KeywordSets are named keyword lists corpora can be counted with instead
+	// of Keywords, given as keywords.<name>=a,b in properties files.
+	KeywordSets   map[string][]string `properties:"-" json:"keyword_sets"`
+	StopWords     []string            `properties:"-" json:"stopwords"`
+	StopWordsFile string              `properties:"stopwords_file" json:"stopwords_file"`
+
+	LanguageDetection bool `properties:"language_detection" json:"language_detection"`
+	// Transliterate matches Serbian Cyrillic and Latin spellings of a word
+	// as the same word.
+	Transliterate bool `properties:"transliterate" json:"transliterate"`
+
+	UserAgent string `properties:"user_agent" json:"user_agent"`
+	// IgnoreRobotsTxt turns robots.txt compliance off, IgnoreRobotsTxtHosts
+	// overrides it per host, given as ignore_robots_txt.<host> in properties
+	// files.
+	IgnoreRobotsTxt      bool            `properties:"ignore_robots_txt" json:"ignore_robots_txt"`
+	IgnoreRobotsTxtHosts map[string]bool `properties:"-" json:"ignore_robots_txt_hosts"`
+	// HostConcurrency limits the requests in flight to a single host,
+	// HostConcurrencies overrides it per host, given as
+	// host_concurrency.<host> in properties files.
+	HostConcurrency   int            `properties:"host_concurrency" json:"host_concurrency"`
+	HostConcurrencies map[string]int `properties:"-" json:"host_concurrencies"`
+	CrawlDelayMS      uint64         `properties:"crawl_delay" json:"crawl_delay"`
+
+	// VisitedBloomFilter remembers the urls crawled for a web corpus in a
+	// Bloom filter sized for VisitedCapacity urls instead of an exact set.
+	VisitedBloomFilter       bool    `properties:"visited_bloom_filter" json:"visited_bloom_filter"`
+	VisitedCapacity          int     `properties:"visited_capacity" json:"visited_capacity"`
+	VisitedFalsePositiveRate float64 `properties:"visited_false_positive_rate" json:"visited_false_positive_rate"`
+
+	// WebTextTitle, WebTextMetaDescription and WebTextAlt count the page
+	// title, meta description and image alt text along with the visible text
+	// of web pages.
+	WebTextTitle           bool `properties:"web_text_title" json:"web_text_title"`
+	WebTextMetaDescription bool `properties:"web_text_meta_description" json:"web_text_meta_description"`
+	WebTextAlt             bool `properties:"web_text_alt" json:"web_text_alt"`
+
+	// WebMaxPageSize is the largest web page in bytes that's counted.
+	// WebHeadCheck sends a HEAD request before every page to skip it without
+	// downloading it.
+	WebMaxPageSize int  `properties:"web_max_page_size" json:"web_max_page_size"`
+	WebHeadCheck   bool `properties:"web_head_check" json:"web_head_check"`
+	// ContentTypes routes media types to the html, plain or skip content
+	// handler, given as content_type.<type>/<subtype> in properties files.
+	ContentTypes map[string]string `properties:"-" json:"content_types"`
+
+	// WebCache persists the ETag and Last-Modified validators and counts of
+	// web pages in WebCachePath, so re-crawls send conditional requests.
+	WebCache                bool   `properties:"web_cache" json:"web_cache"`
+	WebCachePath            string `properties:"web_cache_path" json:"web_cache_path"`
+	WebCacheFlushIntervalMS uint64 `properties:"web_cache_flush_interval" json:"web_cache_flush_interval"`
+
+	// WebHTTPProxy and WebHTTPSProxy proxy the web crawler's http and https
+	// requests, http://, https:// and socks5:// proxies are supported. With
+	// neither set HTTP_PROXY, HTTPS_PROXY and NO_PROXY are used instead.
+	WebHTTPProxy  string `properties:"web_http_proxy" json:"web_http_proxy"`
+	WebHTTPSProxy string `properties:"web_https_proxy" json:"web_https_proxy"`
+	// WebNoProxy are the comma separated hosts, domains and cidrs that are
+	// reached without the proxies.
+	WebNoProxy string `properties:"web_no_proxy" json:"web_no_proxy"`
+	// WebCABundle is a pem file of certificates trusted besides the system's.
+	WebCABundle string `properties:"web_ca_bundle" json:"web_ca_bundle"`
+	// WebClientCert and WebClientKey are the pem files of the certificate the
+	// web crawler presents to servers that ask for one.
+	WebClientCert string `properties:"web_client_cert" json:"web_client_cert"`
+	WebClientKey  string `properties:"web_client_key" json:"web_client_key"`
+
+	// WebMaxRedirects caps the redirects followed per page, 0 keeps net/http's
+	// default of 10 and negative values are rejected. WebRedirects is where
+	// they may lead: any, same-host, same-domain or none, which follows none.
+	WebMaxRedirects int    `properties:"web_max_redirects" json:"web_max_redirects"`
+	WebRedirects    string `properties:"web_redirects" json:"web_redirects"`
+	// WebConnectTimeoutMS bounds connecting to a host, WebReadTimeoutMS
+	// bounds receiving the whole response once connected.
+	WebConnectTimeoutMS uint64 `properties:"web_connect_timeout" json:"web_connect_timeout"`
+	WebReadTimeoutMS    uint64 `properties:"web_read_timeout" json:"web_read_timeout"`
+
+	// WebCountErrorPages counts the words of pages answered with a non-2xx
+	// status, e.g. custom 404 pages, and follows their links.
+	WebCountErrorPages bool `properties:"web_count_error_pages" json:"web_count_error_pages"`
+
+	// WebAllowPrivateAddresses lets the web crawler reach loopback, private,
+	// link-local and multicast addresses, WebAllowedAddresses are the ips,
+	// cidrs and hosts it may reach when it can't.
+	WebAllowPrivateAddresses bool     `properties:"web_allow_private_addresses" json:"web_allow_private_addresses"`
+	WebAllowedAddresses      []string `properties:"-" json:"web_allowed_addresses"`
+
+	// AuthProfiles are the credentials sent to the hosts of each profile,
+	// given as auth.<profile>.<field> in properties files.
+	AuthProfiles map[string]*AuthProfile `properties:"-" json:"auth_profiles"`
+}
+
+// AuthProfile holds the credentials of a set of hosts. Values starting with
+// $ are read from the environment, so secrets can stay out of config files.
+type AuthProfile struct {
+	// Hosts the profile applies to, *.example.com matches every subdomain.
+	Hosts         []string          `json:"hosts"`
+	Headers       map[string]string `json:"headers"`
+	BearerToken   string            `json:"bearer_token"`
+	BasicUser     string            `json:"basic_user"`
+	BasicPassword string            `json:"basic_password"`
+	// CookieFile is a cookie jar in the Netscape cookies.txt format.
+	CookieFile string `json:"cookie_file"`
+}
+
+// String names the configured credentials without their values, so a
+// profile is safe to log.
+func (p *AuthProfile) String() string {
+	credentials := make([]string, 0, len(p.Headers)+3)
+	for name := range p.Headers {
+		credentials = append(credentials, "header "+name)
+	}
+	if p.BearerToken != "" {
+		credentials = append(credentials, "bearer token")
+	}
+	if p.BasicUser != "" {
+		credentials = append(credentials, "basic auth")
+	}
+	if p.CookieFile != "" {
+		credentials = append(credentials, "cookie file "+p.CookieFile)
+	}
+	sort.Strings(credentials)
+	return fmt.Sprintf("hosts %s: %s", strings.Join(p.Hosts, ","), strings.Join(credentials, ", "))
+}
+
+func (p *AuthProfile) expandEnv() {
+	expand := func(value string) string {
+		if strings.HasPrefix(value, "$") {
+			return os.ExpandEnv(value)
+		}
+		return value
+	}
+
+	for name, value := range p.Headers {
+		p.Headers[name] = expand(value)
+	}
+	p.BearerToken = expand(p.BearerToken)
+	p.BasicUser = expand(p.BasicUser)
+	p.BasicPassword = expand(p.BasicPassword)
+}
+
+// authProfiles collects the auth.<profile>.<field> properties, headers are
+// given as auth.<profile>.header.<name>.
+func authProfiles(properties AppConfigProperties) (map[string]*AuthProfile, error) {
+	profiles := make(map[string]*AuthProfile)
+	for key, value := range prefixedProperties(properties, "auth.") {
+		dot := strings.Index(key, ".")
+		if dot < 0 {
+			return nil, errors.Errorf("invalid auth property auth.%s", key)
+		}
+		name, field := key[:dot], key[dot+1:]
+
+		profile, ok := profiles[name]
+		if !ok {
+			profile = &AuthProfile{Headers: make(map[string]string)}
+			profiles[name] = profile
+		}
+
+		switch {
+		case field == "hosts":
+			profile.Hosts = strings.Split(value, ",")
+		case field == "bearer_token":
+			profile.BearerToken = value
+		case field == "basic_user":
+			profile.BasicUser = value
+		case field == "basic_password":
+			profile.BasicPassword = value
+		case field == "cookie_file":
+			profile.CookieFile = value
+		case strings.HasPrefix(field, "header."):
+			profile.Headers[strings.TrimPrefix(field, "header.")] = value
+		default:
+			return nil, errors.Errorf("unknown auth property auth.%s", key)
+		}
+	}
+	return profiles, nil
+}
+
+const (
+	DefaultCrawlerPoolSize    = 200
+	DefaultRetrieverPoolSize  = 50
+	DefaultJobTimeoutMS       = 60000
+	DefaultAdaptiveMinSize    = 1
+	DefaultAdaptiveMaxSize    = 1000
+	DefaultAdaptiveInterval   = 5000
+	DefaultContextWindow      = 40
+	DefaultContextMaxMatches  = 10
+	DefaultNGramSketchSize    = 1000
+	DefaultCooccurrenceWindow = 10
+	DefaultDiscoverExactLimit = 100000
+	DefaultDiscoverTopK       = 1000
+	DefaultCountMinWidth      = 1 << 16
+	DefaultCountMinDepth      = 4
+	DefaultIndexFlushInterval = 30000
+	DefaultUserAgent          = "kids1/1.0"
+	DefaultHostConcurrency    = 2
+	DefaultVisitedCapacity    = 1000000
+	DefaultVisitedFPRate      = 0.001
+	DefaultWebMaxPageSize     = 10 * 1024 * 1024
+	DefaultWebCachePath       = "./web_cache"
+	DefaultWebMaxRedirects    = 10
+	DefaultWebRedirects       = "any"
+	DefaultWebConnectTimeout  = 10000
+	DefaultWebReadTimeout     = 30000
+)
+
+// validate rejects the values setDefaults doesn't stand in for.
+func (sc *SystemConfig) validate() error {
+	if sc.WebMaxRedirects < 0 {
+		return errors.Errorf("web_max_redirects can't be negative, got %d", sc.WebMaxRedirects)
+	}
+	return nil
+}
+
+func (sc *SystemConfig) setDefaults() {
+	for _, profile := range sc.AuthProfiles {
+		profile.expandEnv()
+	}
+	if sc.FileCrawlerPoolSize <= 0 {
+		sc.FileCrawlerPoolSize = DefaultCrawlerPoolSize
+	}
+	if sc.WebCrawlerPoolSize <= 0 {
+		sc.WebCrawlerPoolSize = DefaultCrawlerPoolSize
+	}
+	if sc.RetrieverPoolSize <= 0 {
+		sc.RetrieverPoolSize = DefaultRetrieverPoolSize
+	}
+	if sc.FileJobTimeoutMS == 0 {
+		sc.FileJobTimeoutMS = DefaultJobTimeoutMS
+	}
+	if sc.WebJobTimeoutMS == 0 {
+		sc.WebJobTimeoutMS = DefaultJobTimeoutMS
+	}
+	if sc.RetrieverJobTimeoutMS == 0 {
+		sc.RetrieverJobTimeoutMS = DefaultJobTimeoutMS
+	}
+	if sc.AdaptiveMinPoolSize <= 0 {
+		sc.AdaptiveMinPoolSize = DefaultAdaptiveMinSize
+	}
+	if sc.AdaptiveMaxPoolSize <= 0 {
+		sc.AdaptiveMaxPoolSize = DefaultAdaptiveMaxSize
+	}
+	if sc.AdaptiveIntervalMS == 0 {
+		sc.AdaptiveIntervalMS = DefaultAdaptiveInterval
+	}
+	if sc.ContextWindow <= 0 {
+		sc.ContextWindow = DefaultContextWindow
+	}
+	if sc.ContextMaxMatches <= 0 {
+		sc.ContextMaxMatches = DefaultContextMaxMatches
+	}
+	if sc.NGramSketchSize <= 0 {
+		sc.NGramSketchSize = DefaultNGramSketchSize
+	}
+	if sc.CooccurrenceWindow <= 0 {
+		sc.CooccurrenceWindow = DefaultCooccurrenceWindow
+	}
+	if sc.DiscoverExactLimit <= 0 {
+		sc.DiscoverExactLimit = DefaultDiscoverExactLimit
+	}
+	if sc.DiscoverTopK <= 0 {
+		sc.DiscoverTopK = DefaultDiscoverTopK
+	}
+	if sc.CountMinWidth <= 0 {
+		sc.CountMinWidth = DefaultCountMinWidth
+	}
+	if sc.CountMinDepth <= 0 {
+		sc.CountMinDepth = DefaultCountMinDepth
+	}
+	if sc.IndexFlushIntervalMS == 0 {
+		sc.IndexFlushIntervalMS = DefaultIndexFlushInterval
+	}
+	if sc.UserAgent == "" {
+		sc.UserAgent = DefaultUserAgent
+	}
+	if sc.HostConcurrency <= 0 {
+		sc.HostConcurrency = DefaultHostConcurrency
+	}
+	if sc.VisitedCapacity <= 0 {
+		sc.VisitedCapacity = DefaultVisitedCapacity
+	}
+	if sc.VisitedFalsePositiveRate <= 0 || sc.VisitedFalsePositiveRate >= 1 {
+		sc.VisitedFalsePositiveRate = DefaultVisitedFPRate
+	}
+	if sc.WebMaxPageSize <= 0 {
+		sc.WebMaxPageSize = DefaultWebMaxPageSize
+	}
+	if sc.WebCachePath == "" {
+		sc.WebCachePath = DefaultWebCachePath
+	}
+	if sc.WebCacheFlushIntervalMS == 0 {
+		sc.WebCacheFlushIntervalMS = DefaultIndexFlushInterval
+	}
+	if sc.WebMaxRedirects == 0 {
+		sc.WebMaxRedirects = DefaultWebMaxRedirects
+	}
+	if sc.WebRedirects == "" {
+		sc.WebRedirects = DefaultWebRedirects
+	}
+	if sc.WebConnectTimeoutMS == 0 {
+		sc.WebConnectTimeoutMS = DefaultWebConnectTimeout
+	}
+	if sc.WebReadTimeoutMS == 0 {
+		sc.WebReadTimeoutMS = DefaultWebReadTimeout
+	}
 }
 
 func LoadEnvFile(path string) error {
@@ -80,6 +424,50 @@ func loadPropertiesFile(path string) (*SystemConfig, error) {
 	keywordsArr := strings.Split(keywordsStr, ",")
 	sc.Keywords = keywordsArr
 
+	sc.Stemmers = prefixedProperties(properties, "stemmer.")
+
+	sc.KeywordSets = make(map[string][]string)
+	for name, keywords := range prefixedProperties(properties, "keywords.") {
+		sc.KeywordSets[name] = strings.Split(keywords, ",")
+	}
+
+	if stopWords, ok := properties["stopwords"].(string); ok && stopWords != "" {
+		sc.StopWords = strings.Split(stopWords, ",")
+	}
+
+	sc.IgnoreRobotsTxtHosts = make(map[string]bool)
+	for host, value := range prefixedProperties(properties, "ignore_robots_txt.") {
+		ignore, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid ignore_robots_txt.%s", host)
+		}
+		sc.IgnoreRobotsTxtHosts[host] = ignore
+	}
+
+	sc.ContentTypes = prefixedProperties(properties, "content_type.")
+
+	if allowed, ok := properties["web_allowed_addresses"].(string); ok && allowed != "" {
+		sc.WebAllowedAddresses = strings.Split(allowed, ",")
+	}
+
+	sc.AuthProfiles, err = authProfiles(properties)
+	if err != nil {
+		return nil, err
+	}
+
+	sc.HostConcurrencies = make(map[string]int)
+	for host, value := range prefixedProperties(properties, "host_concurrency.") {
+		concurrency, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid host_concurrency.%s", host)
+		}
+		sc.HostConcurrencies[host] = concurrency
+	}
+
+	if err := sc.validate(); err != nil {
+		return nil, err
+	}
+	sc.setDefaults()
 	return sc, nil
 }
 
@@ -104,6 +492,10 @@ func loadJsonFile(path string) (*SystemConfig, error) {
 		return nil, errors.Wrap(err, "couldn't unmarshal json config: ")
 	}
 
+	if err := sc.validate(); err != nil {
+		return nil, err
+	}
+	sc.setDefaults()
 	return sc, nil
 }
 
@@ -122,6 +514,11 @@ func readPropertiesFile(path string) (AppConfigProperties, error) {
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := scanner.Text()
+		// lines starting with # or ! are comments, e.g. commented out
+		// examples of optional keys.
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "!") {
+			continue
+		}
 		if equal := strings.Index(line, "="); equal >= 0 {
 			if key := strings.TrimSpace(line[:equal]); len(key) > 0 {
 				value := ""
@@ -140,6 +537,21 @@ func readPropertiesFile(path string) (AppConfigProperties, error) {
 	return config, nil
 }
 
+// prefixedProperties collects the string properties whose keys start with
+// prefix, keyed by the rest of the key.
+func prefixedProperties(properties AppConfigProperties, prefix string) map[string]string {
+	values := make(map[string]string)
+	for key, value := range properties {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if str, ok := value.(string); ok {
+			values[strings.TrimPrefix(key, prefix)] = str
+		}
+	}
+	return values
+}
+
 func unmarshal(input AppConfigProperties, output interface{}) error {
 	decoderConfig := &mapstructure.DecoderConfig{
 		TagName:          "properties",
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -38,3 +40,31 @@ func TestPropertiesConfig(t *testing.T) {
 	assert.Equal(t, systemConfig.FileScanningSizeLimit, sc.FileScanningSizeLimit)
 	assert.Equal(t, systemConfig.Keywords, sc.Keywords)
 }
+
+func TestPropertiesComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.properties")
+	assert.NoError(t, os.WriteFile(path, []byte("hop_count=2\n# content_type.application/json=plain\n  ! stemmer=porter\n"), 0644))
+
+	properties, err := readPropertiesFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, AppConfigProperties{"hop_count": "2"}, properties)
+}
+
+func TestPropertiesMaxRedirects(t *testing.T) {
+	load := func(maxRedirects string) (*SystemConfig, error) {
+		path := filepath.Join(t.TempDir(), "config.properties")
+		assert.NoError(t, os.WriteFile(path, []byte("keywords=one\nweb_max_redirects="+maxRedirects+"\n"), 0644))
+		return loadPropertiesFile(path)
+	}
+
+	sc, err := load("3")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, sc.WebMaxRedirects)
+
+	sc, err = load("0")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultWebMaxRedirects, sc.WebMaxRedirects)
+
+	_, err = load("-1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package crawler
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/text"
+	"github.com/l2cup/kids1/pkg/text/stem"
+)
+
+type CounterFactoryConfig struct {
+	Counter *text.CounterConfig
+	// DefaultStemmer is used for corpora without a stemmer of their own.
+	DefaultStemmer string
+	// Stemmers maps corpus names to the stemmer configured for them.
+	Stemmers map[string]string
+	// KeywordSets are the named keyword lists corpora can be counted with.
+	KeywordSets map[string][]string
+}
+
+// CounterFactory builds and caches the counters of every corpus, since the
+// way words are matched can differ between corpora.
+type CounterFactory struct {
+	config *CounterFactoryConfig
+
+	mutex    sync.Mutex
+	counters map[string]*text.Counter
+}
+
+func NewCounterFactory(c *CounterFactoryConfig) *CounterFactory {
+	return &CounterFactory{
+		config:   c,
+		counters: make(map[string]*text.Counter),
+	}
+}
+
+func (f *CounterFactory) Counter(corpusName string, options *dispatcher.CorpusOptions) (*text.Counter, error) {
+	stemmerName := f.config.DefaultStemmer
+	if name, ok := f.config.Stemmers[corpusName]; ok {
+		stemmerName = name
+	}
+
+	keywordSet := ""
+	if options != nil {
+		if options.Stemmer != "" {
+			stemmerName = options.Stemmer
+		}
+		keywordSet = options.KeywordSet
+	}
+
+	defer f.mutex.Unlock()
+	f.mutex.Lock()
+
+	key := stemmerName + "\x00" + keywordSet
+	if counter, ok := f.counters[key]; ok {
+		return counter, nil
+	}
+
+	stemmer, err := stem.New(stemmerName)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't create stemmer")
+	}
+
+	config := *f.config.Counter
+	config.Stemmer = stemmer
+
+	if keywordSet != "" {
+		keywords, ok := f.config.KeywordSets[keywordSet]
+		if !ok {
+			return nil, errors.Errorf("keyword set %q doesn't exist", keywordSet)
+		}
+		config.Keywords = keywords
+	}
+
+	counter := text.NewCounter(&config)
+	f.counters[key] = counter
+	return counter, nil
+}
@@ -1,23 +1,36 @@
 package crawler
 
 import (
+	"github.com/l2cup/kids1/pkg/dispatcher"
 	"github.com/l2cup/kids1/pkg/errors"
 	"github.com/l2cup/kids1/pkg/log"
+	"github.com/l2cup/kids1/pkg/pool"
 	"github.com/l2cup/kids1/pkg/runner"
 )
 
 type DirCrawler interface {
 	runner.Runner
-	AddDirectoryPath(path string) errors.Error
+	AddDirectoryPath(path string, options *dispatcher.CorpusOptions) errors.Error
 }
 
 type FileCrawler interface {
 	runner.Runner
+	pool.Resizer
 }
 
+// Kinds of seed lists a web crawl can start from.
+const (
+	SitemapSeedList = "sitemap"
+	FeedSeedList    = "feed"
+)
+
 type WebCrawler interface {
 	runner.Runner
-	AddWebPage(url string)
+	pool.Resizer
+	AddWebPage(url string, options *dispatcher.CorpusOptions)
+	// AddSeedList crawls the pages listed by a sitemap, sitemap index, rss or
+	// atom feed, kind is the kind of list expected at the url.
+	AddSeedList(url, kind string, options *dispatcher.CorpusOptions)
 }
 
 type Crawler struct {
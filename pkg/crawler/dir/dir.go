@@ -31,6 +31,7 @@ type crawlerImplementation struct {
 	lastModifiedCache map[string]time.Time
 	mutex             sync.Mutex
 	directories       []string
+	options           map[string]*dispatcher.CorpusOptions
 
 	done chan struct{}
 }
@@ -51,6 +52,7 @@ func NewCrawlerImplementation(c *Config) crawler.DirCrawler {
 		done:              make(chan struct{}),
 		sleepTime:         sleepTime,
 		directories:       make([]string, 0),
+		options:           make(map[string]*dispatcher.CorpusOptions),
 		mutex:             sync.Mutex{},
 		prefix:            c.Prefix,
 	}
@@ -74,7 +76,7 @@ func (ci *crawlerImplementation) Start() {
 	}
 }
 
-func (ci *crawlerImplementation) AddDirectoryPath(path string) errors.Error {
+func (ci *crawlerImplementation) AddDirectoryPath(path string, options *dispatcher.CorpusOptions) errors.Error {
 	defer ci.mutex.Unlock()
 	ci.mutex.Lock()
 
@@ -92,8 +94,9 @@ func (ci *crawlerImplementation) AddDirectoryPath(path string) errors.Error {
 	if !exists {
 		ci.directories = append(ci.directories, path)
 	}
+	ci.options[path] = options
 
-	go ci.crawlDir(path, true)
+	go ci.crawlDir(path, true, options)
 
 	return errors.Nil()
 }
@@ -111,11 +114,11 @@ func (ci *crawlerImplementation) crawl() {
 	}
 
 	for _, dir := range ci.directories {
-		ci.crawlDir(dir, false)
+		ci.crawlDir(dir, false, ci.options[dir])
 	}
 }
 
-func (ci *crawlerImplementation) crawlDir(dirPath string, clearCache bool) {
+func (ci *crawlerImplementation) crawlDir(dirPath string, clearCache bool, options *dispatcher.CorpusOptions) {
 
 	err := filepath.Walk(dirPath, func(path string, f os.FileInfo, err error) error {
 		if !strings.HasPrefix(f.Name(), ci.prefix) || !f.IsDir() {
@@ -125,13 +128,13 @@ func (ci *crawlerImplementation) crawlDir(dirPath string, clearCache bool) {
 		lastMod, exists := ci.lastModifiedCache[path]
 		if !exists {
 			ci.lastModifiedCache[path] = f.ModTime()
-			ci.pushJob(f.Name(), path, f.Size())
+			ci.pushJob(f.Name(), path, f.Size(), options)
 			return filepath.SkipDir
 		}
 
 		if lastMod != f.ModTime() || clearCache {
 			ci.lastModifiedCache[path] = f.ModTime()
-			ci.pushJob(f.Name(), path, f.Size())
+			ci.pushJob(f.Name(), path, f.Size(), options)
 		}
 
 		return filepath.SkipDir
@@ -142,13 +145,14 @@ func (ci *crawlerImplementation) crawlDir(dirPath string, clearCache bool) {
 	}
 }
 
-func (ci *crawlerImplementation) pushJob(corpusName, path string, size int64) {
+func (ci *crawlerImplementation) pushJob(corpusName, path string, size int64, options *dispatcher.CorpusOptions) {
 	ci.dispatcher.Push(&dispatcher.Job{
 		Type: dispatcher.DirectoryJobType,
 		Payload: &dispatcher.DirectoryCrawlerPayload{
 			CorpusName: corpusName,
 			Path:       path,
 			Size:       size,
+			Options:    options,
 		},
 	})
 }
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -12,6 +11,8 @@ import (
 	"github.com/Jeffail/tunny"
 	"github.com/l2cup/kids1/pkg/crawler"
 	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/index"
+	"github.com/l2cup/kids1/pkg/pool"
 	"github.com/l2cup/kids1/pkg/result"
 	"github.com/l2cup/kids1/pkg/runner"
 )
@@ -21,8 +22,12 @@ type Config struct {
 	RunnerRegistrator    runner.Registrator
 	Dispatcher           *dispatcher.Dispatcher
 	ResultRetriever      result.Retriever
-	Keywords             []string
+	Index                index.Index
+	Counters             *crawler.CounterFactory
 	QueuedFilesSizeLimit uint64
+	PoolSize             int
+	JobTimeoutMS         uint64
+	Tuner                *pool.TunerConfig
 }
 
 var _ crawler.FileCrawler = (*crawlerImplementation)(nil)
@@ -31,30 +36,53 @@ var _ runner.Runner = (*crawlerImplementation)(nil)
 type crawlerImplementation struct {
 	*crawler.Crawler
 
-	keywords             []string
+	counters             *crawler.CounterFactory
 	dispatcher           *dispatcher.Dispatcher
 	resultRetriever      result.Retriever
+	index                index.Index
 	pool                 *tunny.Pool
+	tuner                *pool.Tuner
+	jobTimeout           time.Duration
 	queuedFilesSizeLimit uint64
 
 	done chan struct{}
 }
 
 func NewCrawlerImplementation(c *Config) crawler.FileCrawler {
+	jobTimeout, err := time.ParseDuration(fmt.Sprintf("%dms", c.JobTimeoutMS))
+	if err != nil {
+		c.Crawler.Logger.Fatal("couldn't parse file job timeout duration", "err", err, "duration", c.JobTimeoutMS)
+	}
+
 	ci := &crawlerImplementation{
 		Crawler:              c.Crawler,
 		dispatcher:           c.Dispatcher,
 		resultRetriever:      c.ResultRetriever,
-		keywords:             c.Keywords,
+		index:                c.Index,
+		counters:             c.Counters,
 		done:                 make(chan struct{}),
 		queuedFilesSizeLimit: c.QueuedFilesSizeLimit,
+		jobTimeout:           jobTimeout,
 	}
 
 	c.RunnerRegistrator.Register(ci)
-	ci.pool = tunny.NewFunc(200, ci.wordCounterWorker)
+	ci.pool = tunny.NewFunc(c.PoolSize, ci.wordCounterWorker)
+
+	if c.Tuner != nil {
+		c.Tuner.Resizer = ci
+		ci.tuner = pool.NewTuner(c.Tuner)
+	}
 	return ci
 }
 
+func (ci *crawlerImplementation) PoolSize() int {
+	return ci.pool.GetSize()
+}
+
+func (ci *crawlerImplementation) SetPoolSize(size int) {
+	ci.pool.SetSize(size)
+}
+
 func (ci *crawlerImplementation) Start() {
 	for {
 		select {
@@ -85,6 +113,7 @@ func (ci *crawlerImplementation) handleDirectory(payload dispatcher.JobPayload)
 			CorpusName: dirPayload.CorpusName,
 			Path:       path,
 			Size:       f.Size(),
+			Options:    dirPayload.Options,
 		})
 		ci.Logger.Debug("appended file payload", "payload", filePayloads)
 		return nil
@@ -95,7 +124,12 @@ func (ci *crawlerImplementation) handleDirectory(payload dispatcher.JobPayload)
 		return
 	}
 
-	ci.resultRetriever.InitializeSummary(dispatcher.FileJobType, dirPayload.CorpusName, len(filePayloads), time.Time{})
+	created := ci.resultRetriever.InitializeSummary(dispatcher.FileJobType, dirPayload.CorpusName, len(filePayloads), time.Time{},
+		dirPayload.Options.KeywordSetName())
+	// files deleted since the last crawl mustn't stay searchable.
+	if created && ci.index != nil {
+		ci.index.RemoveCorpus(dispatcher.FileJobType, dirPayload.CorpusName)
+	}
 
 	minimumJobCount := uint64(dirPayload.Size) / ci.queuedFilesSizeLimit
 	if minimumJobCount == 0 {
@@ -128,7 +162,7 @@ func (ci *crawlerImplementation) startWCWorker(payload []*dispatcher.FileCrawler
 		return
 	}
 
-	_, err := ci.pool.ProcessTimed(payload, 60*time.Second)
+	_, err := ci.pool.ProcessTimed(payload, ci.jobTimeout)
 	ci.Logger.Debug("started timed file process with payload", "payload", payload)
 
 	if err == tunny.ErrJobTimedOut {
@@ -157,30 +191,54 @@ func (ci *crawlerImplementation) wordCounterWorker(payload interface{}) interfac
 }
 
 func (ci *crawlerImplementation) countWords(filePayload *dispatcher.FileCrawlerPayload) error {
+	// every file has to reach the summary, even the ones that can't be
+	// counted, or the corpus never finishes.
+	counter, err := ci.counters.Counter(filePayload.CorpusName, filePayload.Options)
+	if err != nil {
+		ci.resultRetriever.UpdateSummary(&result.Results{
+			JobType:    dispatcher.FileJobType,
+			CorpusName: filePayload.CorpusName,
+			Source:     filePayload.Path,
+		})
+		return errors.Wrap(err, "couldn't get counter for corpus")
+	}
+
+	start := time.Now()
 	data, err := os.ReadFile(filePayload.Path)
 	if err != nil {
+		ci.resultRetriever.UpdateSummary(&result.Results{
+			JobType:    dispatcher.FileJobType,
+			CorpusName: filePayload.CorpusName,
+			Source:     filePayload.Path,
+		})
 		return errors.Wrap(err, fmt.Sprintf("couldn't read file, path %s", filePayload.Path))
 	}
+	ci.tuner.Observe(int64(len(data)), time.Since(start))
 
 	ci.Logger.Debug("starting word count for file", "file", filePayload.Path)
-	results := make(map[string]int64)
-	for _, word := range ci.keywords {
-		results[word] = 0
-	}
+	count := counter.Count(data)
 
-	words := strings.Fields(string(data))
-	for _, w := range words {
-		if result, ok := results[w]; ok {
-			results[w] = result + 1
-		}
-	}
+	ci.Logger.Debug("ended word count for file", "file", filePayload.Path, "results", count.Results)
 
-	ci.Logger.Debug("ended word count for file", "file", filePayload.Path, "results", results)
+	if ci.index != nil {
+		ci.index.Add(&index.Document{
+			JobType:    dispatcher.FileJobType,
+			CorpusName: filePayload.CorpusName,
+			Source:     filePayload.Path,
+			Words:      count.Words,
+		})
+	}
 
 	ci.resultRetriever.UpdateSummary(&result.Results{
 		JobType:    dispatcher.FileJobType,
 		CorpusName: filePayload.CorpusName,
-		Results:    results,
+		Source:     filePayload.Path,
+		Tokens:     count.Tokens,
+		Language:   count.Language,
+		Results:    count.Results,
+		Matches:    count.Matches,
+		Analysis:   count.Analysis,
+		Vocabulary: count.Vocabulary,
 	})
 
 	return nil
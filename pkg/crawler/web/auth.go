@@ -0,0 +1,205 @@
+package web
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/publicsuffix"
+)
+
+// AuthProfile holds the credentials sent to a set of hosts, a host starting
+// with *. matches every subdomain of the rest.
+type AuthProfile struct {
+	Hosts         []string
+	Headers       map[string]string
+	BearerToken   string
+	BasicUser     string
+	BasicPassword string
+	// CookieFile is a cookie jar in the Netscape cookies.txt format.
+	CookieFile string
+}
+
+type authProfile struct {
+	name   string
+	header http.Header
+	jar    http.CookieJar
+}
+
+// auth finds the credentials of a request's host. Credentials are only ever
+// written to request headers, never to the logs.
+type auth struct {
+	hosts     map[string]*authProfile
+	wildcards map[string]*authProfile
+}
+
+func newAuth(profiles map[string]*AuthProfile) (*auth, error) {
+	a := &auth{
+		hosts:     make(map[string]*authProfile),
+		wildcards: make(map[string]*authProfile),
+	}
+
+	for name, p := range profiles {
+		profile := &authProfile{name: name, header: make(http.Header)}
+		for key, value := range p.Headers {
+			profile.header.Set(key, value)
+		}
+		if p.BearerToken != "" {
+			profile.header.Set("Authorization", "Bearer "+p.BearerToken)
+		}
+		if p.BasicUser != "" {
+			credentials := base64.StdEncoding.EncodeToString([]byte(p.BasicUser + ":" + p.BasicPassword))
+			profile.header.Set("Authorization", "Basic "+credentials)
+		}
+		if p.CookieFile != "" {
+			jar, err := loadCookieFile(p.CookieFile)
+			if err != nil {
+				return nil, errors.Wrapf(err, "couldn't load cookie file of auth profile %s", name)
+			}
+			profile.jar = jar
+		}
+
+		for _, h := range p.Hosts {
+			h = strings.ToLower(strings.TrimSpace(h))
+			if h == "" {
+				continue
+			}
+			if strings.HasPrefix(h, "*.") {
+				a.wildcards[strings.TrimPrefix(h, "*.")] = profile
+				continue
+			}
+			a.hosts[h] = profile
+		}
+	}
+
+	return a, nil
+}
+
+// profile returns the profile of the host, exact hosts take precedence over
+// wildcards and longer wildcards over shorter ones.
+func (a *auth) profile(host string) *authProfile {
+	if a == nil {
+		return nil
+	}
+
+	host = strings.ToLower(host)
+	if p, ok := a.hosts[host]; ok {
+		return p
+	}
+	host = (&url.URL{Host: host}).Hostname()
+	if p, ok := a.hosts[host]; ok {
+		return p
+	}
+
+	for domain := host; ; {
+		dot := strings.Index(domain, ".")
+		if dot < 0 {
+			return nil
+		}
+		domain = domain[dot+1:]
+		if p, ok := a.wildcards[domain]; ok {
+			return p
+		}
+	}
+}
+
+// apply sets the credentials of the url's host on the request header.
+func (a *auth) apply(u *url.URL, header http.Header) {
+	p := a.profile(u.Host)
+	if p == nil {
+		return
+	}
+
+	for key, values := range p.header {
+		header[key] = values
+	}
+	if p.jar == nil {
+		return
+	}
+	for _, cookie := range p.jar.Cookies(u) {
+		if existing := header.Get("Cookie"); existing != "" {
+			header.Set("Cookie", existing+"; "+cookie.String())
+			continue
+		}
+		header.Set("Cookie", cookie.String())
+	}
+}
+
+// redirect moves the credentials of a redirected request to the new host.
+// net/http copies the headers of the first request to every redirect, so
+// when the host changes the first host's credentials are removed and the
+// new host's applied.
+func (a *auth) redirect(req *http.Request, via []*http.Request) {
+	origin := via[0].URL
+	if req.URL.Host == origin.Host {
+		return
+	}
+
+	req.Header.Del("Authorization")
+	req.Header.Del("Cookie")
+	if p := a.profile(origin.Host); p != nil {
+		for key := range p.header {
+			req.Header.Del(key)
+		}
+	}
+	a.apply(req.URL, req.Header)
+}
+
+// loadCookieFile reads a Netscape cookies.txt file, the format curl and
+// browser extensions export, into a cookie jar.
+func loadCookieFile(path string) (http.CookieJar, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		// curl marks http only cookies with a #HttpOnly_ prefix.
+		text = strings.TrimPrefix(text, "#HttpOnly_")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) != 7 {
+			return nil, errors.Errorf("invalid cookie on line %d", line)
+		}
+
+		domain := strings.TrimPrefix(fields[0], ".")
+		cookie := &http.Cookie{
+			Name:   fields[5],
+			Value:  fields[6],
+			Path:   fields[2],
+			Secure: strings.EqualFold(fields[3], "TRUE"),
+		}
+		if strings.EqualFold(fields[1], "TRUE") {
+			cookie.Domain = domain
+		}
+		if expires, err := strconv.ParseInt(fields[4], 10, 64); err == nil && expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+
+		scheme := "http"
+		if cookie.Secure {
+			scheme = "https"
+		}
+		jar.SetCookies(&url.URL{Scheme: scheme, Host: domain, Path: "/"}, []*http.Cookie{cookie})
+	}
+
+	return jar, scanner.Err()
+}
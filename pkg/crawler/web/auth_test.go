@@ -0,0 +1,124 @@
+package web
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func authHeader(t *testing.T, a *auth, rawURL string) http.Header {
+	u, err := url.Parse(rawURL)
+	assert.NoError(t, err)
+
+	header := make(http.Header)
+	a.apply(u, header)
+	return header
+}
+
+func TestAuthProfiles(t *testing.T) {
+	a, err := newAuth(map[string]*AuthProfile{
+		"wiki": {
+			Hosts:       []string{"wiki.example.com", "*.wiki.example.com"},
+			Headers:     map[string]string{"x-team": "search"},
+			BearerToken: "token",
+		},
+		"intranet": {
+			Hosts:         []string{"Intranet.example.com:8080"},
+			BasicUser:     "user",
+			BasicPassword: "password",
+		},
+	})
+	assert.NoError(t, err)
+
+	header := authHeader(t, a, "https://wiki.example.com/page")
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+	assert.Equal(t, "search", header.Get("X-Team"))
+
+	header = authHeader(t, a, "https://docs.wiki.example.com/page")
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+
+	header = authHeader(t, a, "http://intranet.example.com:8080/")
+	assert.Equal(t, "Basic dXNlcjpwYXNzd29yZA==", header.Get("Authorization"))
+
+	assert.Empty(t, authHeader(t, a, "https://example.com/"))
+	assert.Empty(t, authHeader(t, a, "https://notwiki.example.com/"))
+}
+
+func TestAuthNil(t *testing.T) {
+	var a *auth
+	assert.Empty(t, authHeader(t, a, "https://example.com/"))
+}
+
+func TestAuthCookieFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "auth")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cookies.txt")
+	cookies := "# Netscape HTTP Cookie File\n" +
+		".example.com\tTRUE\t/\tFALSE\t0\tsession\tabc\n" +
+		"#HttpOnly_wiki.example.com\tFALSE\t/private\tTRUE\t0\tprivate\txyz\n"
+	assert.NoError(t, ioutil.WriteFile(path, []byte(cookies), 0600))
+
+	a, err := newAuth(map[string]*AuthProfile{
+		"wiki": {Hosts: []string{"*.example.com"}, CookieFile: path},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "session=abc", authHeader(t, a, "http://wiki.example.com/").Get("Cookie"))
+	assert.Equal(t, "private=xyz; session=abc", authHeader(t, a, "https://wiki.example.com/private/page").Get("Cookie"))
+	assert.Equal(t, "session=abc", authHeader(t, a, "https://docs.example.com/private/page").Get("Cookie"))
+
+	_, err = newAuth(map[string]*AuthProfile{"missing": {CookieFile: filepath.Join(dir, "missing.txt")}})
+	assert.Error(t, err)
+}
+
+func TestAuthRedirect(t *testing.T) {
+	received := make(chan http.Header, 1)
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header
+	}))
+	defer other.Close()
+
+	wiki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/landing", http.StatusFound)
+	}))
+	defer wiki.Close()
+
+	wikiURL, err := url.Parse(wiki.URL)
+	assert.NoError(t, err)
+	otherURL, err := url.Parse(other.URL)
+	assert.NoError(t, err)
+
+	a, err := newAuth(map[string]*AuthProfile{
+		"wiki":  {Hosts: []string{wikiURL.Host}, Headers: map[string]string{"X-Api-Key": "secret"}, BearerToken: "token"},
+		"other": {Hosts: []string{otherURL.Host}, Headers: map[string]string{"X-Other-Key": "other"}},
+	})
+	assert.NoError(t, err)
+
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		a.redirect(req, via)
+		return nil
+	}}
+
+	req, err := http.NewRequest(http.MethodGet, wiki.URL, nil)
+	assert.NoError(t, err)
+	a.apply(req.URL, req.Header)
+	req.Header.Set("User-Agent", "kids1/1.0")
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	header := <-received
+	assert.Empty(t, header.Get("X-Api-Key"))
+	assert.Empty(t, header.Get("Authorization"))
+	assert.Equal(t, "other", header.Get("X-Other-Key"))
+	assert.Equal(t, "kids1/1.0", header.Get("User-Agent"))
+}
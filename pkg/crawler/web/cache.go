@@ -0,0 +1,159 @@
+package web
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/l2cup/kids1/pkg/log"
+	"github.com/l2cup/kids1/pkg/persist"
+	"github.com/l2cup/kids1/pkg/runner"
+	"github.com/l2cup/kids1/pkg/text"
+)
+
+const cacheFileName = "web_cache.gob"
+
+type CacheConfig struct {
+	// Path is the directory the cache is persisted to.
+	Path            string
+	FlushIntervalMS uint64
+}
+
+// cacheEntry holds the validators of a fetched url, the count made from it
+// and its links, reused when a conditional request answers 304 Not Modified.
+type cacheEntry struct {
+	ETag         string
+	LastModified string
+	Count        *text.Count
+	Links        []string
+	// FinalURL is where the page's redirects ended.
+	FinalURL string
+	// FetchedAt is when the page was last downloaded or revalidated.
+	FetchedAt time.Time
+}
+
+var _ runner.Runner = (*cache)(nil)
+
+// cache keeps the entries of the fetched urls and persists them on a ticker,
+// like the index does.
+type cache struct {
+	logger        *log.Logger
+	path          string
+	flushInterval time.Duration
+
+	mutex   sync.RWMutex
+	changes persist.Changes
+	entries map[string]*cacheEntry
+
+	done chan struct{}
+}
+
+func newCache(logger *log.Logger, c *CacheConfig) *cache {
+	flushInterval, err := time.ParseDuration(fmt.Sprintf("%dms", c.FlushIntervalMS))
+	if err != nil {
+		logger.Fatal("couldn't parse web cache flush interval duration", "err", err, "duration", c.FlushIntervalMS)
+	}
+
+	ca := &cache{
+		logger:        logger,
+		path:          c.Path,
+		flushInterval: flushInterval,
+		entries:       make(map[string]*cacheEntry),
+		done:          make(chan struct{}),
+	}
+
+	if err := ca.load(); err != nil {
+		logger.Error("[web cache] couldn't load cache, starting empty", "err", err, "path", ca.path)
+	}
+	return ca
+}
+
+// cacheKey separates the counts of a url made with different counters.
+func cacheKey(url string, counter *text.Counter) string {
+	return url + "\x00" + counter.Signature()
+}
+
+func (ca *cache) Start() {
+	ticker := time.NewTicker(ca.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ca.flush()
+		case <-ca.done:
+			ca.flush()
+			return
+		}
+	}
+}
+
+func (ca *cache) Stop() {
+	ca.done <- struct{}{}
+}
+
+func (ca *cache) get(key string) *cacheEntry {
+	defer ca.mutex.RUnlock()
+	ca.mutex.RLock()
+	return ca.entries[key]
+}
+
+func (ca *cache) put(key string, entry *cacheEntry) {
+	defer ca.mutex.Unlock()
+	ca.mutex.Lock()
+	ca.entries[key] = entry
+	ca.changes.Change()
+}
+
+func (ca *cache) flush() {
+	if err := persist.Flush(&ca.mutex, &ca.changes, ca.save); err != nil {
+		ca.logger.Error("[web cache] couldn't persist cache", "err", err, "path", ca.path)
+	}
+}
+
+func (ca *cache) save() error {
+	if err := os.MkdirAll(ca.path, 0755); err != nil {
+		return errors.Wrap(err, "couldn't create web cache directory")
+	}
+
+	tmp, err := os.CreateTemp(ca.path, cacheFileName+".*")
+	if err != nil {
+		return errors.Wrap(err, "couldn't create web cache file")
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(ca.entries); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "couldn't encode web cache")
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "couldn't write web cache file")
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(ca.path, cacheFileName))
+}
+
+func (ca *cache) load() error {
+	file, err := os.Open(filepath.Join(ca.path, cacheFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	entries := make(map[string]*cacheEntry)
+	if err := gob.NewDecoder(file).Decode(&entries); err != nil {
+		return errors.Wrap(err, "couldn't decode web cache")
+	}
+
+	ca.entries = entries
+	return nil
+}
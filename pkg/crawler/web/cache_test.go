@@ -0,0 +1,37 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/l2cup/kids1/pkg/testutil"
+	"github.com/l2cup/kids1/pkg/text"
+)
+
+func TestCachePersists(t *testing.T) {
+	logger := testutil.Logger(t)
+
+	counter := text.NewCounter(&text.CounterConfig{
+		Keywords: []string{"core"},
+		Analysis: &text.AnalyzerConfig{NGramSizes: []int{2}, SketchSize: 10, Window: 1},
+	})
+	count := counter.Count([]byte("the core team core"))
+
+	dir := t.TempDir()
+	ca := newCache(logger, &CacheConfig{Path: dir, FlushIntervalMS: 1000})
+	key := cacheKey("https://example.com/", counter)
+	ca.put(key, &cacheEntry{ETag: `"v1"`, Count: count, Links: []string{"https://example.com/a"}})
+	ca.flush()
+
+	reloaded := newCache(logger, &CacheConfig{Path: dir, FlushIntervalMS: 1000})
+	entry := reloaded.get(key)
+	assert.NotNil(t, entry)
+	assert.Equal(t, `"v1"`, entry.ETag)
+	assert.Equal(t, count.Results, entry.Count.Results)
+	assert.Equal(t, count.Analysis.NGrams[2].Top(0), entry.Count.Analysis.NGrams[2].Top(0))
+	assert.Equal(t, []string{"https://example.com/a"}, entry.Links)
+
+	other := text.NewCounter(&text.CounterConfig{Keywords: []string{"team"}})
+	assert.Nil(t, reloaded.get(cacheKey("https://example.com/", other)))
+}
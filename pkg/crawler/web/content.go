@@ -0,0 +1,161 @@
+package web
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	HTMLContentHandler  = "html"
+	PlainContentHandler = "plain"
+	SkipContentHandler  = "skip"
+)
+
+// ContentHandler turns a downloaded body into the text that's counted.
+type ContentHandler func(body []byte) ([]byte, error)
+
+type ContentConfig struct {
+	// MaxSize is the largest body in bytes that's counted, larger pages are
+	// skipped by their Content-Length or once the download passes it.
+	MaxSize int
+	// HeadCheck sends a HEAD request before every page to skip unsupported
+	// and too large pages without downloading them.
+	HeadCheck bool
+	// ContentTypes routes media types to the html, plain or skip handler,
+	// e.g. application/json to plain. A type/* key matches a whole type.
+	ContentTypes map[string]string
+	// Handlers adds handlers for media types, e.g. to extract the text of
+	// pdfs, they take precedence over ContentTypes.
+	Handlers map[string]ContentHandler
+}
+
+var errUnsupportedContent = errors.New("unsupported content type")
+var errContentTooLarge = errors.New("content too large")
+
+// isSkipped reports whether the page was left out by its content type or size
+// rather than failing to be read.
+func isSkipped(err error) bool {
+	cause := errors.Cause(err)
+	return cause == errUnsupportedContent || cause == errContentTooLarge
+}
+
+// content picks the handler of every fetched page by its Content-Type.
+type content struct {
+	maxSize   int
+	headCheck bool
+	handlers  map[string]ContentHandler
+}
+
+func newContent(c *ContentConfig, text *TextConfig) (*content, error) {
+	if c == nil {
+		c = &ContentConfig{}
+	}
+
+	html := func(body []byte) ([]byte, error) { return extractText(body, text) }
+	plain := func(body []byte) ([]byte, error) { return body, nil }
+
+	ct := &content{
+		maxSize:   c.MaxSize,
+		headCheck: c.HeadCheck,
+		handlers: map[string]ContentHandler{
+			"text/html":             html,
+			"application/xhtml+xml": html,
+			"text/plain":            plain,
+			"text/markdown":         plain,
+			"text/x-markdown":       plain,
+		},
+	}
+
+	for mediaType, name := range c.ContentTypes {
+		switch strings.ToLower(name) {
+		case HTMLContentHandler:
+			ct.handlers[mediaType] = html
+		case PlainContentHandler:
+			ct.handlers[mediaType] = plain
+		case SkipContentHandler:
+			ct.handlers[mediaType] = nil
+		default:
+			return nil, errors.Errorf("unknown content handler %q for %s", name, mediaType)
+		}
+	}
+
+	for mediaType, handler := range c.Handlers {
+		ct.handlers[mediaType] = handler
+	}
+	return ct, nil
+}
+
+// handler returns the handler of the Content-Type, nil if the type is
+// skipped.
+func (c *content) handler(contentType string) ContentHandler {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+
+	if handler, ok := c.handlers[mediaType]; ok {
+		return handler
+	}
+	if i := strings.Index(mediaType, "/"); i >= 0 {
+		return c.handlers[mediaType[:i]+"/*"]
+	}
+	return nil
+}
+
+// withMaxSize returns the content with a different size limit, for crawls
+// overriding the system one.
+func (c *content) withMaxSize(maxSize int) *content {
+	if maxSize == c.maxSize {
+		return c
+	}
+	sized := *c
+	sized.maxSize = maxSize
+	return &sized
+}
+
+// bodySize is the MaxBodySize of the collectors, a byte over the limit so
+// a body cut off by colly can be told apart from one that fits.
+func (c *content) bodySize() int {
+	if c.maxSize <= 0 {
+		return 0
+	}
+	return c.maxSize + 1
+}
+
+// onResponseHeaders aborts the download of pages that won't be counted.
+func (c *content) onResponseHeaders(r *colly.Response) {
+	if contentType := r.Headers.Get("Content-Type"); contentType != "" && c.handler(contentType) == nil {
+		r.Request.Abort()
+		return
+	}
+
+	if c.maxSize > 0 {
+		length, err := strconv.Atoi(r.Headers.Get("Content-Length"))
+		if err == nil && length > c.maxSize {
+			r.Request.Abort()
+		}
+	}
+}
+
+// text returns the text of a downloaded page to count.
+func (c *content) text(r *colly.Response) ([]byte, error) {
+	if c.maxSize > 0 && len(r.Body) > c.maxSize {
+		return nil, errContentTooLarge
+	}
+
+	contentType := r.Headers.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(r.Body)
+	}
+
+	handler := c.handler(contentType)
+	if handler == nil {
+		return nil, errors.Wrap(errUnsupportedContent, contentType)
+	}
+	return handler(r.Body)
+}
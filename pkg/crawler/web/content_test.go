@@ -0,0 +1,68 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func response(contentType, body string) *colly.Response {
+	headers := http.Header{}
+	if contentType != "" {
+		headers.Set("Content-Type", contentType)
+	}
+	return &colly.Response{Body: []byte(body), Headers: &headers}
+}
+
+func TestContentText(t *testing.T) {
+	c, err := newContent(&ContentConfig{
+		MaxSize:      64,
+		ContentTypes: map[string]string{"application/json": "plain", "text/markdown": "skip"},
+		Handlers: map[string]ContentHandler{
+			"application/pdf": func(body []byte) ([]byte, error) { return []byte("pdf text"), nil },
+		},
+	}, nil)
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		contentType string
+		body        string
+		text        string
+	}{
+		{"text/html; charset=utf-8", "<p>core <script>x</script></p>", "core"},
+		{"text/plain", "core <b>", "core <b>"},
+		{"application/json", `{"core": 1}`, `{"core": 1}`},
+		{"application/pdf", "%PDF", "pdf text"},
+		{"", "<html><body>sniffed</body></html>", "sniffed"},
+	} {
+		text, err := c.text(response(tc.contentType, tc.body))
+		assert.NoError(t, err, tc.contentType)
+		assert.Equal(t, tc.text, strings.TrimSpace(string(text)), tc.contentType)
+	}
+
+	for _, tc := range []struct {
+		contentType string
+		body        string
+	}{
+		{"image/png", "\x89PNG"},
+		{"text/markdown", "# core"},
+		{"text/plain", strings.Repeat("a", 65)},
+	} {
+		_, err := c.text(response(tc.contentType, tc.body))
+		assert.Error(t, err, tc.contentType)
+	}
+
+	_, err = newContent(&ContentConfig{ContentTypes: map[string]string{"a/b": "unknown"}}, nil)
+	assert.Error(t, err)
+}
+
+func TestContentWildcard(t *testing.T) {
+	c, err := newContent(&ContentConfig{ContentTypes: map[string]string{"text/*": "plain"}}, nil)
+	assert.NoError(t, err)
+
+	assert.NotNil(t, c.handler("text/csv"))
+	assert.Nil(t, c.handler("image/png"))
+}
@@ -0,0 +1,126 @@
+package web
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type TextConfig struct {
+	// Title, MetaDescription and AltText add the page title, the meta
+	// description and the alt text of images to the visible text.
+	Title           bool
+	MetaDescription bool
+	AltText         bool
+}
+
+// skipped elements never hold visible text.
+var skipped = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Iframe:   true,
+	atom.Svg:      true,
+}
+
+// blocks are the elements that break words apart, text in inline elements
+// like <b> continues the surrounding word.
+var blocks = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Br: true, atom.Dd: true, atom.Div: true, atom.Dl: true, atom.Dt: true,
+	atom.Fieldset: true, atom.Figcaption: true, atom.Figure: true, atom.Footer: true,
+	atom.Form: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true,
+	atom.H5: true, atom.H6: true, atom.Header: true, atom.Hr: true, atom.Li: true,
+	atom.Main: true, atom.Nav: true, atom.Ol: true, atom.Option: true, atom.P: true,
+	atom.Pre: true, atom.Section: true, atom.Table: true, atom.Td: true, atom.Th: true,
+	atom.Tr: true, atom.Ul: true, atom.Button: true, atom.Label: true,
+}
+
+// extractText returns the text of an html page a reader would see, without
+// tags, attributes, scripts and styles.
+func extractText(body []byte, c *TextConfig) ([]byte, error) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		c = &TextConfig{}
+	}
+
+	var b bytes.Buffer
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if skipped[n.DataAtom] {
+				return
+			}
+
+			switch n.DataAtom {
+			case atom.Title:
+				if c.Title {
+					writeBlock(&b, textOf(n))
+				}
+				return
+			case atom.Meta:
+				if c.MetaDescription && strings.EqualFold(attr(n, "name"), "description") {
+					writeBlock(&b, attr(n, "content"))
+				}
+				return
+			case atom.Img:
+				if c.AltText {
+					writeBlock(&b, attr(n, "alt"))
+				}
+				return
+			}
+		}
+
+		block := n.Type == html.ElementNode && blocks[n.DataAtom]
+		if block {
+			b.WriteByte('\n')
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if block {
+			b.WriteByte('\n')
+		}
+	}
+	walk(doc)
+
+	return b.Bytes(), nil
+}
+
+func writeBlock(b *bytes.Buffer, text string) {
+	if text == "" {
+		return
+	}
+	b.WriteByte('\n')
+	b.WriteString(text)
+	b.WriteByte('\n')
+}
+
+func textOf(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode {
+			b.WriteString(child.Data)
+		}
+	}
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package web
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Core docs</title>
+	<meta name="description" content="About the core">
+	<style>.core { color: red }</style>
+	<script>var core = "core";</script>
+</head>
+<body class="core">
+	<h1>Intro</h1><p>The <b>Core</b> team<br>ships.</p>
+	<noscript>enable core</noscript>
+	<img src="core.png" alt="core diagram">
+	<ul><li>one</li><li>two</li></ul>
+</body>
+</html>`
+
+func TestExtractText(t *testing.T) {
+	text, err := extractText([]byte(testPage), &TextConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Intro", "The", "Core", "team", "ships.", "one", "two"}, strings.Fields(string(text)))
+
+	text, err = extractText([]byte(testPage), &TextConfig{Title: true, MetaDescription: true, AltText: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"Core", "docs", "About", "the", "core",
+		"Intro", "The", "Core", "team", "ships.",
+		"core", "diagram", "one", "two",
+	}, strings.Fields(string(text)))
+}
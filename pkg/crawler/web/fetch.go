@@ -0,0 +1,114 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/l2cup/kids1/pkg/dispatcher"
+)
+
+// defaultMaxRedirects is net/http's own limit, used when none is set.
+const defaultMaxRedirects = 10
+
+type FetchConfig struct {
+	// MaxRedirects caps the redirects followed per page, 0 keeps net/http's
+	// default, Redirects none follows none.
+	MaxRedirects int
+	// Redirects is one of the dispatcher redirect policies.
+	Redirects        string
+	ConnectTimeoutMS uint64
+	ReadTimeoutMS    uint64
+}
+
+// fetchPolicy is how the pages of a crawl are fetched, the system config
+// with the crawl's options applied over it.
+type fetchPolicy struct {
+	maxRedirects   int
+	redirects      string
+	connectTimeout time.Duration
+	readTimeout    time.Duration
+	maxBodySize    int
+}
+
+func newFetchPolicy(c *FetchConfig, maxBodySize int, options *dispatcher.CorpusOptions) *fetchPolicy {
+	if c == nil {
+		c = &FetchConfig{}
+	}
+
+	p := &fetchPolicy{
+		maxRedirects:   c.MaxRedirects,
+		redirects:      c.Redirects,
+		connectTimeout: time.Duration(c.ConnectTimeoutMS) * time.Millisecond,
+		readTimeout:    time.Duration(c.ReadTimeoutMS) * time.Millisecond,
+		maxBodySize:    maxBodySize,
+	}
+
+	if options != nil && options.Fetch != nil {
+		f := options.Fetch
+		if f.MaxRedirects > 0 {
+			p.maxRedirects = f.MaxRedirects
+		}
+		if f.Redirects != "" {
+			p.redirects = f.Redirects
+		}
+		if f.ConnectTimeoutMS > 0 {
+			p.connectTimeout = time.Duration(f.ConnectTimeoutMS) * time.Millisecond
+		}
+		if f.ReadTimeoutMS > 0 {
+			p.readTimeout = time.Duration(f.ReadTimeoutMS) * time.Millisecond
+		}
+		if f.MaxBodySize > 0 {
+			p.maxBodySize = f.MaxBodySize
+		}
+	}
+
+	if p.maxRedirects <= 0 {
+		p.maxRedirects = defaultMaxRedirects
+	}
+	return p
+}
+
+// requestTimeout bounds a whole request, the transport enforces the connect
+// timeout and the read timeout for the response headers.
+func (p *fetchPolicy) requestTimeout() time.Duration {
+	if p.readTimeout <= 0 {
+		return 0
+	}
+	return p.connectTimeout + p.readTimeout
+}
+
+// checkRedirect follows a redirect if the policy allows it, the error ends
+// the request with the redirect unfollowed.
+func (p *fetchPolicy) checkRedirect(req *http.Request, via []*http.Request) error {
+	if p.redirects == dispatcher.NoRedirects {
+		return errors.Errorf("not following redirect to %s, redirects are disabled", req.URL)
+	}
+	if len(via) > p.maxRedirects {
+		return errors.Errorf("stopped after %d redirects", p.maxRedirects)
+	}
+
+	origin := via[0].URL
+	switch p.redirects {
+	case dispatcher.SameHostRedirects:
+		if !strings.EqualFold(req.URL.Hostname(), origin.Hostname()) {
+			return errors.Errorf("not following redirect to %s, it leaves host %s", req.URL, origin.Hostname())
+		}
+	case dispatcher.SameDomainRedirects:
+		domain, err := registrableDomain(origin.Hostname())
+		if err != nil {
+			domain = origin.Hostname()
+		}
+		if !sameDomain(req.URL.Hostname(), domain) {
+			return errors.Errorf("not following redirect to %s, it leaves domain %s", req.URL, domain)
+		}
+	}
+	return nil
+}
+
+func sameDomain(host, domain string) bool {
+	host, domain = strings.ToLower(host), strings.ToLower(domain)
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
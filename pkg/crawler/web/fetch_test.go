@@ -0,0 +1,76 @@
+package web
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/l2cup/kids1/pkg/dispatcher"
+)
+
+func redirect(t *testing.T, to string, via ...string) (*http.Request, []*http.Request) {
+	req, err := http.NewRequest(http.MethodGet, to, nil)
+	assert.NoError(t, err)
+
+	requests := make([]*http.Request, 0, len(via))
+	for _, u := range via {
+		r, err := http.NewRequest(http.MethodGet, u, nil)
+		assert.NoError(t, err)
+		requests = append(requests, r)
+	}
+	return req, requests
+}
+
+func TestFetchPolicy(t *testing.T) {
+	c := &FetchConfig{MaxRedirects: 5, Redirects: dispatcher.AnyRedirects, ConnectTimeoutMS: 1000, ReadTimeoutMS: 2000}
+
+	p := newFetchPolicy(c, 100, nil)
+	assert.Equal(t, &fetchPolicy{
+		maxRedirects:   5,
+		redirects:      dispatcher.AnyRedirects,
+		connectTimeout: time.Second,
+		readTimeout:    2 * time.Second,
+		maxBodySize:    100,
+	}, p)
+	assert.Equal(t, 3*time.Second, p.requestTimeout())
+
+	p = newFetchPolicy(c, 100, &dispatcher.CorpusOptions{Fetch: &dispatcher.WebFetch{
+		Redirects:     dispatcher.SameHostRedirects,
+		ReadTimeoutMS: 500,
+		MaxBodySize:   10,
+	}})
+	assert.Equal(t, 5, p.maxRedirects)
+	assert.Equal(t, dispatcher.SameHostRedirects, p.redirects)
+	assert.Equal(t, time.Second, p.connectTimeout)
+	assert.Equal(t, 500*time.Millisecond, p.readTimeout)
+	assert.Equal(t, 10, p.maxBodySize)
+
+	assert.Equal(t, defaultMaxRedirects, newFetchPolicy(nil, 0, nil).maxRedirects)
+}
+
+func TestFetchPolicyRedirects(t *testing.T) {
+	policy := func(redirects string) *fetchPolicy {
+		return &fetchPolicy{maxRedirects: 2, redirects: redirects}
+	}
+
+	req, via := redirect(t, "https://example.com/b", "https://example.com/a")
+	assert.NoError(t, policy(dispatcher.AnyRedirects).checkRedirect(req, via))
+	assert.Error(t, policy(dispatcher.NoRedirects).checkRedirect(req, via))
+
+	req, via = redirect(t, "https://example.com/d", "https://example.com/a", "https://example.com/b", "https://example.com/c")
+	assert.Error(t, policy(dispatcher.AnyRedirects).checkRedirect(req, via))
+
+	req, via = redirect(t, "https://other.com/", "https://example.com/a")
+	assert.NoError(t, policy(dispatcher.AnyRedirects).checkRedirect(req, via))
+	assert.Error(t, policy(dispatcher.SameHostRedirects).checkRedirect(req, via))
+	assert.Error(t, policy(dispatcher.SameDomainRedirects).checkRedirect(req, via))
+
+	req, via = redirect(t, "https://docs.example.co.uk/", "https://www.example.co.uk/")
+	assert.Error(t, policy(dispatcher.SameHostRedirects).checkRedirect(req, via))
+	assert.NoError(t, policy(dispatcher.SameDomainRedirects).checkRedirect(req, via))
+
+	req, via = redirect(t, "https://other.co.uk/", "https://www.example.co.uk/")
+	assert.Error(t, policy(dispatcher.SameDomainRedirects).checkRedirect(req, via))
+}
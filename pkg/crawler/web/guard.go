@@ -0,0 +1,193 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+type GuardConfig struct {
+	// AllowPrivate turns the guard off and lets the crawler reach every
+	// address.
+	AllowPrivate bool
+	// Allow are the ips, cidrs and host names that are reached even though
+	// they resolve to a blocked address.
+	Allow []string
+}
+
+// blockedNetworks are the loopback, private, link-local, multicast and
+// otherwise internal ranges a crawled page mustn't be able to reach.
+var blockedNetworks = parseCIDRs(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// blockedError is returned by dials the guard refused.
+type blockedError struct {
+	address string
+}
+
+func (e *blockedError) Error() string {
+	return fmt.Sprintf("address %s is blocked, allowlist it to crawl it", e.address)
+}
+
+// blockedBy returns the guard's error if err comes from a refused dial.
+func blockedBy(err error) (*blockedError, bool) {
+	var blocked *blockedError
+	if errors.As(err, &blocked) {
+		return blocked, true
+	}
+	return nil, false
+}
+
+// guard stops the crawler from reaching internal addresses. It checks the
+// address being dialed, after dns resolution and on every redirect, and the
+// target of every proxied request, so neither a hostile link nor a dns
+// record pointing inside gets through.
+type guard struct {
+	allowPrivate bool
+	hosts        map[string]bool
+	networks     []*net.IPNet
+}
+
+// newGuard creates the guard, nil allows every address. The proxies are
+// always dialed as that's how every proxied request starts, the targets of
+// proxied requests are checked by checkProxied instead.
+func newGuard(c *GuardConfig, proxies ...string) (*guard, error) {
+	g := &guard{hosts: make(map[string]bool)}
+	if c == nil {
+		g.allowPrivate = true
+		return g, nil
+	}
+	g.allowPrivate = c.AllowPrivate
+
+	for _, entry := range c.Allow {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+		case strings.Contains(entry, "/"):
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid allowed network %s", entry)
+			}
+			g.networks = append(g.networks, network)
+		case net.ParseIP(entry) != nil:
+			ip := net.ParseIP(entry)
+			g.networks = append(g.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		default:
+			g.hosts[entry] = true
+		}
+	}
+
+	for _, proxy := range proxies {
+		if u, err := url.Parse(proxy); err == nil && u.Hostname() != "" {
+			g.hosts[strings.ToLower(u.Hostname())] = true
+		}
+	}
+
+	return g, nil
+}
+
+// dialContext wraps the dialer so the resolved address of every connection
+// is checked before it's made.
+func (g *guard) dialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	if g.allowPrivate {
+		return dialer.DialContext
+	}
+
+	guarded := *dialer
+	guarded.Control = func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if !g.allowed(net.ParseIP(host)) {
+			return &blockedError{address: host}
+		}
+		return nil
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if host, _, err := net.SplitHostPort(address); err == nil && g.hosts[strings.ToLower(host)] {
+			return dialer.DialContext(ctx, network, address)
+		}
+		return guarded.DialContext(ctx, network, address)
+	}
+}
+
+// checkProxied checks the target of a request sent through a proxy. The
+// proxy resolves and dials the target itself, so it's resolved here too and
+// every address it has must be allowed.
+func (g *guard) checkProxied(r *http.Request) error {
+	host := strings.ToLower(r.URL.Hostname())
+	if g.allowPrivate || g.hosts[host] {
+		return nil
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if !g.allowed(ip) {
+			return &blockedError{address: host}
+		}
+		return nil
+	}
+
+	addresses, err := net.DefaultResolver.LookupIPAddr(r.Context(), host)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't resolve %s to check it", host)
+	}
+	for _, address := range addresses {
+		if !g.allowed(address.IP) {
+			return &blockedError{address: address.IP.String()}
+		}
+	}
+	return nil
+}
+
+func (g *guard) allowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range g.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package web
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardAllowed(t *testing.T) {
+	g, err := newGuard(&GuardConfig{Allow: []string{"10.1.0.0/16", "192.168.1.10"}})
+	assert.NoError(t, err)
+
+	for ip, allowed := range map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"169.254.169.254":  false,
+		"10.0.0.1":         false,
+		"10.1.2.3":         true,
+		"172.20.0.1":       false,
+		"192.168.1.10":     true,
+		"192.168.1.11":     false,
+		"224.0.0.1":        false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"::ffff:127.0.0.1": false,
+	} {
+		assert.Equal(t, allowed, g.allowed(net.ParseIP(ip)), ip)
+	}
+
+	_, err = newGuard(&GuardConfig{Allow: []string{"10.0.0.0/33"}})
+	assert.Error(t, err)
+}
+
+func TestGuardDial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	get := func(guard *GuardConfig, rawURL string) error {
+		transport, err := newTestTransport(nil, guard)
+		assert.NoError(t, err)
+		resp, err := (&http.Client{Transport: transport}).Get(rawURL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	err := get(&GuardConfig{}, server.URL)
+	blocked, ok := blockedBy(err)
+	assert.True(t, ok)
+	assert.Equal(t, "127.0.0.1", blocked.address)
+
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	assert.NoError(t, err)
+	_, ok = blockedBy(get(&GuardConfig{}, "http://localhost:"+port))
+	assert.True(t, ok)
+
+	assert.NoError(t, get(&GuardConfig{Allow: []string{"127.0.0.0/8"}}, server.URL))
+	assert.NoError(t, get(&GuardConfig{Allow: []string{"localhost"}}, "http://localhost:"+port))
+	assert.NoError(t, get(&GuardConfig{AllowPrivate: true}, server.URL))
+	assert.NoError(t, get(nil, server.URL))
+
+	// the proxy is dialed, but the targets it's asked for are checked.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "proxied")
+	}))
+	defer proxy.Close()
+
+	getProxied := func(guard *GuardConfig, rawURL string) error {
+		transport, err := newTestTransport(&TransportConfig{HTTPProxy: proxy.URL}, guard)
+		assert.NoError(t, err)
+		resp, err := (&http.Client{Transport: transport}).Get(rawURL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	blocked, ok = blockedBy(getProxied(&GuardConfig{}, "http://169.254.169.254/latest/meta-data"))
+	assert.True(t, ok)
+	assert.Equal(t, "169.254.169.254", blocked.address)
+	_, ok = blockedBy(getProxied(&GuardConfig{}, "http://10.0.0.1/"))
+	assert.True(t, ok)
+
+	assert.NoError(t, getProxied(&GuardConfig{}, "http://93.184.216.34/"))
+	assert.NoError(t, getProxied(&GuardConfig{Allow: []string{"10.0.0.0/8"}}, "http://10.0.0.1/"))
+	assert.NoError(t, getProxied(&GuardConfig{AllowPrivate: true}, "http://169.254.169.254/"))
+}
@@ -0,0 +1,168 @@
+package web
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+
+	"github.com/l2cup/kids1/pkg/log"
+)
+
+const robotsTxtTimeout = 10 * time.Second
+
+type HostsConfig struct {
+	UserAgent string
+	// IgnoreRobotsTxt turns robots.txt compliance off for every host,
+	// IgnoreRobotsTxtHosts overrides it per host.
+	IgnoreRobotsTxt      bool
+	IgnoreRobotsTxtHosts map[string]bool
+	// Concurrency is the number of requests in flight to a single host,
+	// Concurrencies overrides it per host.
+	Concurrency   int
+	Concurrencies map[string]int
+	// CrawlDelayMS is the least time between two requests to the same host,
+	// a longer robots.txt Crawl-delay takes precedence.
+	CrawlDelayMS uint64
+}
+
+// hosts keeps the politeness state of every host the web crawler visits,
+// it's shared by all collectors so robots.txt is fetched once per host.
+type hosts struct {
+	logger *log.Logger
+	config *HostsConfig
+	client *http.Client
+
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+type host struct {
+	slots chan struct{}
+
+	robotsOnce sync.Once
+	robots     *robotstxt.Group
+
+	mu    sync.Mutex
+	delay time.Duration
+	next  time.Time
+}
+
+func newHosts(logger *log.Logger, c *HostsConfig, transport http.RoundTripper) *hosts {
+	if c == nil {
+		c = &HostsConfig{}
+	}
+
+	return &hosts{
+		logger: logger,
+		config: c,
+		client: &http.Client{Timeout: robotsTxtTimeout, Transport: transport},
+		hosts:  make(map[string]*host),
+	}
+}
+
+// acquire waits for a free slot and the crawl delay of the url's host. It
+// returns false if robots.txt disallows the url, otherwise the caller must
+// call release once the request is done.
+func (h *hosts) acquire(u *url.URL) (release func(), allowed bool) {
+	hst := h.host(u.Host)
+
+	if h.obeysRobotsTxt(u.Host) {
+		hst.robotsOnce.Do(func() { hst.robots = h.fetchRobotsTxt(u) })
+		if hst.robots != nil && !hst.robots.Test(u.RequestURI()) {
+			return nil, false
+		}
+	}
+
+	hst.slots <- struct{}{}
+
+	delay := hst.delay
+	if hst.robots != nil && hst.robots.CrawlDelay > delay {
+		delay = hst.robots.CrawlDelay
+	}
+
+	hst.mu.Lock()
+	now := time.Now()
+	if hst.next.Before(now) {
+		hst.next = now
+	}
+	wait := hst.next.Sub(now)
+	hst.next = hst.next.Add(delay)
+	hst.mu.Unlock()
+
+	time.Sleep(wait)
+	return func() { <-hst.slots }, true
+}
+
+func (h *hosts) host(name string) *host {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hst, ok := h.hosts[name]
+	if ok {
+		return hst
+	}
+
+	concurrency := h.config.Concurrency
+	if c, ok := h.config.Concurrencies[name]; ok {
+		concurrency = c
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	hst = &host{
+		slots: make(chan struct{}, concurrency),
+		delay: time.Duration(h.config.CrawlDelayMS) * time.Millisecond,
+	}
+	h.hosts[name] = hst
+	return hst
+}
+
+func (h *hosts) obeysRobotsTxt(name string) bool {
+	if ignore, ok := h.config.IgnoreRobotsTxtHosts[name]; ok {
+		return !ignore
+	}
+	return !h.config.IgnoreRobotsTxt
+}
+
+// fetchRobotsTxt returns the robots.txt group for the crawler's user agent,
+// nil means everything is allowed.
+func (h *hosts) fetchRobotsTxt(u *url.URL) *robotstxt.Group {
+	robotsURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+
+	req, err := http.NewRequest(http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		h.logger.Error("couldn't create robots.txt request", "err", err, "url", robotsURL)
+		return nil
+	}
+	req.Header.Set("User-Agent", h.config.UserAgent)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		h.logger.Debug("couldn't fetch robots.txt, allowing everything", "err", err, "url", robotsURL)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	robots, err := robotstxt.FromResponse(resp)
+	if err != nil {
+		h.logger.Debug("couldn't parse robots.txt, allowing everything", "err", err, "url", robotsURL)
+		return nil
+	}
+
+	return robots.FindGroup(userAgentName(h.config.UserAgent))
+}
+
+// userAgentName is the product token robots.txt groups are matched against,
+// e.g. kids1 for "kids1/1.0 (+https://example.com)".
+func userAgentName(userAgent string) string {
+	name := userAgent
+	if i := strings.IndexAny(name, "/ "); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
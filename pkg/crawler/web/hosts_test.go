@@ -0,0 +1,81 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/l2cup/kids1/pkg/testutil"
+)
+
+func TestHostsRobotsTxt(t *testing.T) {
+	var robotsRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&robotsRequests, 1)
+		fmt.Fprint(w, "User-agent: kids1\nDisallow: /private\nCrawl-delay: 0.05\n")
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+
+	h := newHosts(testutil.Logger(t), &HostsConfig{UserAgent: "kids1/1.0", Concurrency: 1}, http.DefaultTransport)
+
+	_, allowed := h.acquire(u.ResolveReference(&url.URL{Path: "/private/page"}))
+	assert.False(t, allowed)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, allowed := h.acquire(u.ResolveReference(&url.URL{Path: "/public"}))
+		assert.True(t, allowed)
+		release()
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&robotsRequests))
+
+	h = newHosts(testutil.Logger(t), &HostsConfig{
+		UserAgent:            "kids1/1.0",
+		Concurrency:          1,
+		IgnoreRobotsTxtHosts: map[string]bool{u.Host: true},
+	}, http.DefaultTransport)
+	release, allowed := h.acquire(u.ResolveReference(&url.URL{Path: "/private/page"}))
+	assert.True(t, allowed)
+	release()
+}
+
+func TestHostsConcurrency(t *testing.T) {
+	h := newHosts(testutil.Logger(t), &HostsConfig{IgnoreRobotsTxt: true, Concurrency: 2}, http.DefaultTransport)
+	u := &url.URL{Scheme: "http", Host: "example.com", Path: "/"}
+
+	first, _ := h.acquire(u)
+	second, _ := h.acquire(u)
+
+	acquired := make(chan struct{})
+	go func() {
+		release, _ := h.acquire(u)
+		close(acquired)
+		release()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired more slots than the host allows")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	first()
+	<-acquired
+	second()
+}
+
+func TestHostsDefaults(t *testing.T) {
+	h := newHosts(testutil.Logger(t), nil, http.DefaultTransport)
+	assert.True(t, h.obeysRobotsTxt("example.com"))
+	assert.Equal(t, 1, cap(h.host("example.com").slots))
+}
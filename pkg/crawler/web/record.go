@@ -0,0 +1,83 @@
+package web
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+
+	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/result"
+)
+
+// fetchRecord collects how a page was fetched for the corpus' fetch report,
+// colly's callbacks fill it in as the response arrives.
+type fetchRecord struct {
+	start time.Time
+
+	mu    sync.Mutex
+	fetch result.Fetch
+}
+
+func newFetchRecord(payload *dispatcher.WebCrawlerPayload, initialHopCount int) *fetchRecord {
+	return &fetchRecord{
+		start: time.Now(),
+		fetch: result.Fetch{
+			URL:    payload.URL,
+			Depth:  initialHopCount - payload.HopCount,
+			Parent: payload.Parent,
+		},
+	}
+}
+
+// register records the status and size of the collector's responses.
+func (f *fetchRecord) register(c *colly.Collector) {
+	c.OnResponseHeaders(func(r *colly.Response) { f.setStatus(r.StatusCode) })
+	c.OnResponse(func(r *colly.Response) {
+		defer f.mu.Unlock()
+		f.mu.Lock()
+		f.fetch.Status = r.StatusCode
+		f.fetch.Bytes = int64(len(r.Body))
+	})
+	c.OnError(func(r *colly.Response, _ error) {
+		if r != nil {
+			f.setStatus(r.StatusCode)
+		}
+	})
+}
+
+func (f *fetchRecord) setStatus(status int) {
+	defer f.mu.Unlock()
+	f.mu.Lock()
+	if status != 0 {
+		f.fetch.Status = status
+	}
+}
+
+// done returns the finished record, err is why the page wasn't counted. A
+// nil record is a page that wasn't fetched, done returns nil for it.
+func (f *fetchRecord) done(err error) *result.Fetch {
+	if f == nil {
+		return nil
+	}
+
+	defer f.mu.Unlock()
+	f.mu.Lock()
+
+	fetch := f.fetch
+	fetch.Latency = time.Since(f.start)
+	if err != nil {
+		fetch.Error = err.Error()
+	}
+	return &fetch
+}
+
+// skip returns the finished record of a page that was left out on purpose,
+// reason is why.
+func (f *fetchRecord) skip(reason error) *result.Fetch {
+	fetch := f.done(nil)
+	if fetch != nil {
+		fetch.Skipped = reason.Error()
+	}
+	return fetch
+}
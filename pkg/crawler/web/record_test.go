@@ -0,0 +1,51 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/l2cup/kids1/pkg/dispatcher"
+)
+
+func TestFetchRecord(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		fmt.Fprint(w, "hello")
+	}))
+	defer server.Close()
+
+	payload := &dispatcher.WebCrawlerPayload{URL: server.URL + "/missing", HopCount: 1, Parent: server.URL}
+	record := newFetchRecord(payload, 3)
+
+	c := colly.NewCollector()
+	c.ParseHTTPErrorResponse = true
+	record.register(c)
+	assert.NoError(t, c.Visit(payload.URL))
+
+	fetch := record.done(errors.New("status 404 Not Found"))
+	assert.Equal(t, payload.URL, fetch.URL)
+	assert.Equal(t, http.StatusNotFound, fetch.Status)
+	assert.Equal(t, "status 404 Not Found", fetch.Error)
+	assert.Equal(t, int64(5), fetch.Bytes)
+	assert.Equal(t, 2, fetch.Depth)
+	assert.Equal(t, server.URL, fetch.Parent)
+	assert.True(t, fetch.Latency > 0)
+	assert.True(t, fetch.Failed())
+
+	skipped := record.skip(errContentTooLarge)
+	assert.Equal(t, "content too large", skipped.Skipped)
+	assert.Empty(t, skipped.Error)
+	assert.False(t, skipped.Failed())
+
+	var unfetched *fetchRecord
+	assert.Nil(t, unfetched.done(nil))
+	assert.Nil(t, unfetched.skip(errContentTooLarge))
+}
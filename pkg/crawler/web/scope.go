@@ -0,0 +1,116 @@
+package web
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/l2cup/kids1/pkg/dispatcher"
+)
+
+// scope is the compiled dispatcher.WebScope of a corpus.
+type scope struct {
+	host       string
+	domain     string
+	pathPrefix string
+	allow      []*regexp.Regexp
+	deny       []*regexp.Regexp
+	maxPages   int64
+	pages      int64
+}
+
+func newScope(seed *url.URL, s *dispatcher.WebScope) (*scope, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	sc := &scope{
+		pathPrefix: s.PathPrefix,
+		maxPages:   int64(s.MaxPages),
+	}
+
+	if s.SameHost {
+		sc.host = seed.Host
+	}
+
+	if s.SameDomain {
+		domain, err := registrableDomain(seed.Hostname())
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't find registrable domain of %s", seed.Hostname())
+		}
+		sc.domain = domain
+	}
+
+	for _, expr := range s.Allow {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid allow expression")
+		}
+		sc.allow = append(sc.allow, re)
+	}
+
+	for _, expr := range s.Deny {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid deny expression")
+		}
+		sc.deny = append(sc.deny, re)
+	}
+
+	return sc, nil
+}
+
+// allows reports whether the normalized url is within the scope, a nil
+// scope allows every url.
+func (s *scope) allows(u *url.URL) bool {
+	if s == nil {
+		return true
+	}
+
+	if s.host != "" && u.Host != s.host {
+		return false
+	}
+
+	if s.domain != "" {
+		domain, err := registrableDomain(u.Hostname())
+		if err != nil || domain != s.domain {
+			return false
+		}
+	}
+
+	if s.pathPrefix != "" && !strings.HasPrefix(u.Path, s.pathPrefix) {
+		return false
+	}
+
+	rawURL := u.String()
+	if len(s.allow) > 0 && !matchesAny(s.allow, rawURL) {
+		return false
+	}
+	return !matchesAny(s.deny, rawURL)
+}
+
+// take spends a page of the corpus budget, it returns false once the budget
+// is spent.
+func (s *scope) take() bool {
+	if s == nil || s.maxPages <= 0 {
+		return true
+	}
+	return atomic.AddInt64(&s.pages, 1) <= s.maxPages
+}
+
+func registrableDomain(host string) (string, error) {
+	return publicsuffix.EffectiveTLDPlusOne(strings.ToLower(host))
+}
+
+func matchesAny(expressions []*regexp.Regexp, s string) bool {
+	for _, re := range expressions {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
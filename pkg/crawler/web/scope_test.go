@@ -0,0 +1,75 @@
+package web
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/l2cup/kids1/pkg/dispatcher"
+)
+
+func TestScope(t *testing.T) {
+	seed, _ := url.Parse("https://docs.example.co.uk/guide/")
+
+	for _, tc := range []struct {
+		scope   dispatcher.WebScope
+		allowed []string
+		denied  []string
+	}{
+		{
+			scope:   dispatcher.WebScope{SameHost: true},
+			allowed: []string{"https://docs.example.co.uk/other"},
+			denied:  []string{"https://blog.example.co.uk/", "https://other.org/"},
+		},
+		{
+			scope:   dispatcher.WebScope{SameDomain: true},
+			allowed: []string{"https://docs.example.co.uk/", "https://blog.example.co.uk/"},
+			denied:  []string{"https://example.com/", "https://other.co.uk/"},
+		},
+		{
+			scope:   dispatcher.WebScope{SameHost: true, PathPrefix: "/guide/"},
+			allowed: []string{"https://docs.example.co.uk/guide/intro"},
+			denied:  []string{"https://docs.example.co.uk/api/"},
+		},
+		{
+			scope: dispatcher.WebScope{
+				Allow: []string{`^https://docs\.example\.co\.uk/`},
+				Deny:  []string{`\.pdf$`, `/private/`},
+			},
+			allowed: []string{"https://docs.example.co.uk/a.html"},
+			denied: []string{
+				"https://docs.example.co.uk/a.pdf",
+				"https://docs.example.co.uk/private/a",
+				"https://other.org/",
+			},
+		},
+	} {
+		s, err := newScope(seed, &tc.scope)
+		assert.NoError(t, err)
+
+		for _, rawURL := range tc.allowed {
+			u, _ := url.Parse(rawURL)
+			assert.True(t, s.allows(u), rawURL)
+		}
+		for _, rawURL := range tc.denied {
+			u, _ := url.Parse(rawURL)
+			assert.False(t, s.allows(u), rawURL)
+		}
+	}
+
+	_, err := newScope(seed, &dispatcher.WebScope{Deny: []string{"("}})
+	assert.Error(t, err)
+}
+
+func TestScopeMaxPages(t *testing.T) {
+	s, err := newScope(&url.URL{Host: "example.com"}, &dispatcher.WebScope{MaxPages: 2})
+	assert.NoError(t, err)
+
+	assert.True(t, s.take())
+	assert.True(t, s.take())
+	assert.False(t, s.take())
+
+	var unlimited *scope
+	assert.True(t, unlimited.take())
+}
@@ -0,0 +1,205 @@
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/l2cup/kids1/pkg/crawler"
+)
+
+const (
+	seedListTimeout = 30 * time.Second
+	// maxSitemapDepth bounds how deep sitemap indexes can nest.
+	maxSitemapDepth = 3
+)
+
+// seed is a page listed by a sitemap or feed, LastModified is zero when the
+// list doesn't say when the page changed.
+type seed struct {
+	URL          string
+	LastModified time.Time
+}
+
+// seedList is a parsed sitemap, sitemap index, rss or atom feed. Sitemaps
+// are the child sitemaps of a sitemap index.
+type seedList struct {
+	Kind     string
+	Seeds    []seed
+	Sitemaps []string
+}
+
+type xmlSitemap struct {
+	XMLName xml.Name
+	URLs    []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+type xmlRSS struct {
+	Items []struct {
+		Link    string `xml:"link"`
+		PubDate string `xml:"pubDate"`
+	} `xml:"channel>item"`
+}
+
+type xmlAtom struct {
+	Entries []struct {
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Updated string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// parseSeedList parses sitemaps, sitemap indexes, rss and atom feeds by the
+// name of their root element.
+func parseSeedList(data []byte) (*seedList, error) {
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &seedList{Kind: crawler.FeedSeedList}
+	switch root {
+	case "urlset", "sitemapindex":
+		list.Kind = crawler.SitemapSeedList
+		sitemap := xmlSitemap{}
+		if err := xml.Unmarshal(data, &sitemap); err != nil {
+			return nil, errors.Wrap(err, "couldn't parse sitemap")
+		}
+		for _, u := range sitemap.URLs {
+			list.Seeds = append(list.Seeds, seed{URL: strings.TrimSpace(u.Loc), LastModified: parseTime(u.LastMod)})
+		}
+		for _, s := range sitemap.Sitemaps {
+			list.Sitemaps = append(list.Sitemaps, strings.TrimSpace(s.Loc))
+		}
+
+	case "rss":
+		rss := xmlRSS{}
+		if err := xml.Unmarshal(data, &rss); err != nil {
+			return nil, errors.Wrap(err, "couldn't parse rss feed")
+		}
+		for _, item := range rss.Items {
+			list.Seeds = append(list.Seeds, seed{URL: strings.TrimSpace(item.Link), LastModified: parseTime(item.PubDate)})
+		}
+
+	case "feed":
+		atom := xmlAtom{}
+		if err := xml.Unmarshal(data, &atom); err != nil {
+			return nil, errors.Wrap(err, "couldn't parse atom feed")
+		}
+		for _, entry := range atom.Entries {
+			for _, link := range entry.Links {
+				if link.Rel == "" || link.Rel == "alternate" {
+					list.Seeds = append(list.Seeds, seed{URL: strings.TrimSpace(link.Href), LastModified: parseTime(entry.Updated)})
+					break
+				}
+			}
+		}
+
+	default:
+		return nil, errors.Errorf("unknown seed list <%s>, expected a sitemap, rss or atom feed", root)
+	}
+
+	return list, nil
+}
+
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", errors.Wrap(err, "couldn't find root element")
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+}
+
+// parseTime parses the dates of sitemaps and feeds, zero if it can't.
+func parseTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// fetchSeedList downloads a sitemap or feed, gzipped sitemaps included.
+func fetchSeedList(client *http.Client, header http.Header, rawURL string, maxSize int) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := readLimited(resp.Body, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return readLimited(reader, maxSize)
+	}
+	return data, nil
+}
+
+// readLimited reads r whole, it fails with errContentTooLarge instead of
+// cutting off what's past maxSize. A maxSize of 0 doesn't limit it.
+func readLimited(r io.Reader, maxSize int) ([]byte, error) {
+	if maxSize <= 0 {
+		return ioutil.ReadAll(r)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSize {
+		return nil, errContentTooLarge
+	}
+	return data, nil
+}
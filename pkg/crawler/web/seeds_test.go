@@ -0,0 +1,122 @@
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/l2cup/kids1/pkg/crawler"
+)
+
+func TestParseSeedList(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		data     string
+		kind     string
+		seeds    []seed
+		sitemaps []string
+	}{
+		{
+			name: "sitemap",
+			data: `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>https://example.com/a</loc><lastmod>2021-03-04</lastmod></url>
+	<url><loc> https://example.com/b </loc></url>
+</urlset>`,
+			kind: crawler.SitemapSeedList,
+			seeds: []seed{
+				{URL: "https://example.com/a", LastModified: time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)},
+				{URL: "https://example.com/b"},
+			},
+		},
+		{
+			name: "sitemap index",
+			data: `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>https://example.com/sitemap1.xml</loc></sitemap>
+</sitemapindex>`,
+			kind:     crawler.SitemapSeedList,
+			sitemaps: []string{"https://example.com/sitemap1.xml"},
+		},
+		{
+			name: "rss",
+			data: `<rss version="2.0"><channel>
+	<item><link>https://example.com/post</link><pubDate>Thu, 04 Mar 2021 10:00:00 +0000</pubDate></item>
+</channel></rss>`,
+			kind: crawler.FeedSeedList,
+			seeds: []seed{
+				{URL: "https://example.com/post", LastModified: time.Date(2021, 3, 4, 10, 0, 0, 0, time.FixedZone("", 0))},
+			},
+		},
+		{
+			name: "atom",
+			data: `<feed xmlns="http://www.w3.org/2005/Atom">
+	<entry>
+		<link rel="edit" href="https://example.com/edit"/>
+		<link href="https://example.com/entry"/>
+		<updated>2021-03-04T10:00:00Z</updated>
+	</entry>
+</feed>`,
+			kind: crawler.FeedSeedList,
+			seeds: []seed{
+				{URL: "https://example.com/entry", LastModified: time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC)},
+			},
+		},
+	} {
+		list, err := parseSeedList([]byte(tc.data))
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, tc.kind, list.Kind, tc.name)
+		assert.Equal(t, len(tc.seeds), len(list.Seeds), tc.name)
+		for i := range tc.seeds {
+			assert.Equal(t, tc.seeds[i].URL, list.Seeds[i].URL, tc.name)
+			assert.True(t, tc.seeds[i].LastModified.Equal(list.Seeds[i].LastModified), tc.name)
+		}
+		assert.Equal(t, tc.sitemaps, list.Sitemaps, tc.name)
+	}
+
+	_, err := parseSeedList([]byte(`<html><body></body></html>`))
+	assert.Error(t, err)
+}
+
+func TestFetchSeedListSize(t *testing.T) {
+	sitemap := []byte(`<urlset><url><loc>https://example.com/` + strings.Repeat("a", 100) + `</loc></url></urlset>`)
+	zipped := &bytes.Buffer{}
+	w := gzip.NewWriter(zipped)
+	_, err := w.Write(sitemap)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sitemap.xml.gz" {
+			w.Write(zipped.Bytes())
+			return
+		}
+		w.Write(sitemap)
+	}))
+	defer server.Close()
+
+	data, err := fetchSeedList(server.Client(), nil, server.URL+"/sitemap.xml", len(sitemap))
+	assert.NoError(t, err)
+	assert.Equal(t, sitemap, data)
+
+	_, err = fetchSeedList(server.Client(), nil, server.URL+"/sitemap.xml", len(sitemap)-1)
+	assert.Equal(t, errContentTooLarge, err)
+
+	data, err = fetchSeedList(server.Client(), nil, server.URL+"/sitemap.xml.gz", len(sitemap))
+	assert.NoError(t, err)
+	assert.Equal(t, sitemap, data)
+
+	// the compressed sitemap fits, but not once it's unzipped.
+	assert.Less(t, zipped.Len(), len(sitemap)-1)
+	_, err = fetchSeedList(server.Client(), nil, server.URL+"/sitemap.xml.gz", len(sitemap)-1)
+	assert.Equal(t, errContentTooLarge, err)
+
+	data, err = fetchSeedList(server.Client(), nil, server.URL+"/sitemap.xml", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, sitemap, data)
+}
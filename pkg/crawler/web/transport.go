@@ -0,0 +1,181 @@
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http/httpproxy"
+)
+
+type TransportConfig struct {
+	// HTTPProxy and HTTPSProxy are the proxies of http and https urls, both
+	// empty falls back to the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	// environment variables.
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+	// CABundle is a pem file of certificates trusted besides the system's.
+	CABundle   string
+	ClientCert string
+	ClientKey  string
+}
+
+// transports are shared by every collector and the robots.txt and seed
+// list clients, so they all go through the same proxies, trust the same
+// certificates and are checked by the same guard. There's a transport per
+// pair of timeouts, so crawls with the same timeouts reuse connections.
+type transports struct {
+	base *http.Transport
+	dial func(ctx context.Context, network, address string) (net.Conn, error)
+
+	mu         sync.Mutex
+	byTimeouts map[timeouts]*http.Transport
+}
+
+type timeouts struct {
+	connect time.Duration
+	read    time.Duration
+}
+
+func newTransports(c *TransportConfig, g *GuardConfig) (*transports, error) {
+	if c == nil {
+		c = &TransportConfig{}
+	}
+
+	proxyConfig := httpproxy.FromEnvironment()
+	if c.HTTPProxy != "" || c.HTTPSProxy != "" {
+		for _, proxy := range []string{c.HTTPProxy, c.HTTPSProxy} {
+			if err := validateProxy(proxy); err != nil {
+				return nil, err
+			}
+		}
+		proxyConfig = &httpproxy.Config{
+			HTTPProxy:  c.HTTPProxy,
+			HTTPSProxy: c.HTTPSProxy,
+			NoProxy:    c.NoProxy,
+		}
+	}
+	proxy := proxyConfig.ProxyFunc()
+
+	guard, err := newGuard(g, proxyConfig.HTTPProxy, proxyConfig.HTTPSProxy)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := newTLSConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transports{
+		base: &http.Transport{
+			Proxy: func(r *http.Request) (*url.URL, error) {
+				proxyURL, err := proxy(r.URL)
+				if err != nil || proxyURL == nil {
+					return proxyURL, err
+				}
+				if err := guard.checkProxied(r); err != nil {
+					return nil, err
+				}
+				return proxyURL, nil
+			},
+			TLSClientConfig:       tlsConfig,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+		dial:       guard.dialContext(&net.Dialer{KeepAlive: 30 * time.Second}),
+		byTimeouts: make(map[timeouts]*http.Transport),
+	}, nil
+}
+
+// get returns the transport that waits up to connectTimeout for a
+// connection and readTimeout for the response headers, 0 waits for as long
+// as the system allows.
+func (t *transports) get(connectTimeout, readTimeout time.Duration) *http.Transport {
+	defer t.mu.Unlock()
+	t.mu.Lock()
+
+	key := timeouts{connect: connectTimeout, read: readTimeout}
+	if transport, ok := t.byTimeouts[key]; ok {
+		return transport
+	}
+
+	transport := t.base.Clone()
+	transport.ResponseHeaderTimeout = readTimeout
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if connectTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, connectTimeout)
+			defer cancel()
+		}
+		return t.dial(ctx, network, address)
+	}
+	t.byTimeouts[key] = transport
+	return transport
+}
+
+// validateProxy checks the proxy's scheme, the error leaves the url out as
+// it can hold the proxy's credentials.
+func validateProxy(proxy string) error {
+	if proxy == "" {
+		return nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return errors.New("invalid proxy url")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+		return nil
+	default:
+		return errors.Errorf("unsupported proxy scheme %q, expected http, https or socks5", u.Scheme)
+	}
+}
+
+func newTLSConfig(c *TransportConfig) (*tls.Config, error) {
+	if c.CABundle == "" && c.ClientCert == "" && c.ClientKey == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{}
+
+	if c.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		pem, err := ioutil.ReadFile(c.CABundle)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't read ca bundle")
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates found in ca bundle %s", c.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.ClientCert != "" || c.ClientKey != "" {
+		if c.ClientCert == "" || c.ClientKey == "" {
+			return nil, errors.New("client certificate and key have to be given together")
+		}
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't load client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,93 @@
+package web
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTransport(c *TransportConfig, g *GuardConfig) (*http.Transport, error) {
+	transports, err := newTransports(c, g)
+	if err != nil {
+		return nil, err
+	}
+	return transports.get(time.Second, time.Second), nil
+}
+
+func TestTransportProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "proxied "+r.URL.String())
+	}))
+	defer proxy.Close()
+
+	transport, err := newTestTransport(&TransportConfig{
+		HTTPProxy: proxy.URL,
+		NoProxy:   "direct.example.com",
+	}, nil)
+	assert.NoError(t, err)
+
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get("http://wiki.example.com/page")
+	assert.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "proxied http://wiki.example.com/page", string(body))
+
+	req, err := http.NewRequest(http.MethodGet, "http://direct.example.com/", nil)
+	assert.NoError(t, err)
+	proxyURL, err := transport.Proxy(req)
+	assert.NoError(t, err)
+	assert.Nil(t, proxyURL)
+
+	_, err = newTestTransport(&TransportConfig{HTTPSProxy: "ftp://proxy.example.com"}, nil)
+	assert.Error(t, err)
+
+	transport, err = newTestTransport(&TransportConfig{HTTPSProxy: "socks5://proxy.example.com:1080"}, nil)
+	assert.NoError(t, err)
+	req, err = http.NewRequest(http.MethodGet, "https://wiki.example.com/", nil)
+	assert.NoError(t, err)
+	proxyURL, err = transport.Proxy(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "socks5://proxy.example.com:1080", proxyURL.String())
+}
+
+func TestTransportCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "transport")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bundle := filepath.Join(dir, "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, ioutil.WriteFile(bundle, certificate, 0600))
+
+	transport, err := newTestTransport(nil, nil)
+	assert.NoError(t, err)
+	_, err = (&http.Client{Transport: transport}).Get(server.URL)
+	assert.Error(t, err)
+
+	transport, err = newTestTransport(&TransportConfig{CABundle: bundle}, nil)
+	assert.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = newTestTransport(&TransportConfig{CABundle: filepath.Join(dir, "missing.pem")}, nil)
+	assert.Error(t, err)
+	_, err = newTestTransport(&TransportConfig{ClientCert: bundle}, nil)
+	assert.Error(t, err)
+}
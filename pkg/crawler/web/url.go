@@ -0,0 +1,86 @@
+package web
+
+import (
+	"net/url"
+	"strings"
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// resolveURL resolves a link found on a page against the page's base url,
+// which is the page url unless the page sets <base href>. Links that can't
+// be crawled, like mailto:, javascript: and tel: links, aren't resolved.
+func resolveURL(base *url.URL, href string) (*url.URL, bool) {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") {
+		return nil, false
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return nil, false
+	}
+
+	u := base.ResolveReference(ref)
+	if _, ok := defaultPorts[strings.ToLower(u.Scheme)]; !ok {
+		return nil, false
+	}
+
+	return normalizeURL(u), true
+}
+
+// normalizeURL returns a copy of u with a lowercase scheme and host, without
+// the fragment and the scheme's default port and with sorted query
+// parameters, so the same page is always written the same way.
+func normalizeURL(u *url.URL) *url.URL {
+	n := *u
+	n.Scheme = strings.ToLower(n.Scheme)
+	n.Fragment = ""
+	n.RawFragment = ""
+
+	host, port := strings.ToLower(n.Hostname()), n.Port()
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" && port != defaultPorts[n.Scheme] {
+		host += ":" + port
+	}
+	n.Host = host
+
+	if n.Path == "" {
+		n.Path = "/"
+		n.RawPath = ""
+	}
+
+	if n.RawQuery != "" {
+		n.RawQuery = n.Query().Encode()
+	}
+	n.ForceQuery = false
+
+	return &n
+}
+
+// parseURL parses and normalizes an absolute http or https url.
+func parseURL(rawURL string) (*url.URL, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+	if _, ok := defaultPorts[strings.ToLower(u.Scheme)]; !ok {
+		return nil, false
+	}
+	return normalizeURL(u), true
+}
+
+// seedURL normalizes the url a web corpus is started from, it's returned
+// as is if it isn't an absolute http or https url.
+func seedURL(rawURL string) string {
+	u, ok := parseURL(rawURL)
+	if !ok {
+		return rawURL
+	}
+	return u.String()
+}
@@ -0,0 +1,41 @@
+package web
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveURL(t *testing.T) {
+	base, err := url.Parse("https://Example.com/docs/guide/index.html")
+	assert.NoError(t, err)
+
+	for href, expected := range map[string]string{
+		"page.html":                  "https://example.com/docs/guide/page.html",
+		"../x":                       "https://example.com/docs/x",
+		"/root":                      "https://example.com/root",
+		"//Other.org/x":              "https://other.org/x",
+		"http://other.org:80/a#frag": "http://other.org/a",
+		"https://other.org:443":      "https://other.org/",
+		"https://other.org:8443/a":   "https://other.org:8443/a",
+		"?b=2&a=1":                   "https://example.com/docs/guide/index.html?a=1&b=2",
+		"page.html#top":              "https://example.com/docs/guide/page.html",
+	} {
+		u, ok := resolveURL(base, href)
+		assert.True(t, ok, href)
+		assert.Equal(t, expected, u.String(), href)
+	}
+
+	for _, href := range []string{
+		"#top",
+		"",
+		"mailto:someone@example.com",
+		"javascript:void(0)",
+		"tel:+381111234567",
+		"ftp://example.com/file",
+	} {
+		_, ok := resolveURL(base, href)
+		assert.False(t, ok, href)
+	}
+}
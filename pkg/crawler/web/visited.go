@@ -0,0 +1,77 @@
+package web
+
+import (
+	"sync"
+
+	"github.com/l2cup/kids1/pkg/sketch"
+)
+
+type VisitedConfig struct {
+	// BloomFilter keeps the visited urls of a corpus in a Bloom filter
+	// instead of a set, for crawls too large to remember every url. A false
+	// positive skips a url that wasn't crawled yet.
+	BloomFilter       bool
+	Capacity          int
+	FalsePositiveRate float64
+}
+
+// urlSet is implemented by both the exact set and sketch.Bloom.
+type urlSet interface {
+	// Add adds the url and reports whether it was already present.
+	Add(url string) bool
+}
+
+type exactSet map[string]struct{}
+
+func (s exactSet) Add(url string) bool {
+	if _, ok := s[url]; ok {
+		return true
+	}
+	s[url] = struct{}{}
+	return false
+}
+
+// visited remembers the normalized urls crawled for every web corpus, so a
+// page is fetched and counted at most once per corpus refresh.
+type visited struct {
+	config  *VisitedConfig
+	mu      sync.Mutex
+	corpora map[string]urlSet
+}
+
+func newVisited(c *VisitedConfig) *visited {
+	if c == nil {
+		c = &VisitedConfig{}
+	}
+	return &visited{
+		config:  c,
+		corpora: make(map[string]urlSet),
+	}
+}
+
+// reset forgets the urls visited for the corpus, it's called when the
+// corpus is refreshed.
+func (v *visited) reset(corpusName string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.config.BloomFilter {
+		v.corpora[corpusName] = sketch.NewBloom(v.config.Capacity, v.config.FalsePositiveRate)
+	} else {
+		v.corpora[corpusName] = make(exactSet)
+	}
+}
+
+// visit marks the url as visited and reports whether it's the first visit
+// in the corpus.
+func (v *visited) visit(corpusName, url string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	urls, ok := v.corpora[corpusName]
+	if !ok {
+		urls = make(exactSet)
+		v.corpora[corpusName] = urls
+	}
+	return !urls.Add(url)
+}
@@ -0,0 +1,25 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVisited(t *testing.T) {
+	for _, config := range []*VisitedConfig{
+		{},
+		{BloomFilter: true, Capacity: 100, FalsePositiveRate: 0.001},
+	} {
+		v := newVisited(config)
+		v.reset("corpus")
+
+		assert.True(t, v.visit("corpus", "https://example.com/"))
+		assert.False(t, v.visit("corpus", "https://example.com/"))
+		assert.True(t, v.visit("other", "https://example.com/"))
+		assert.True(t, v.visit("corpus", "https://example.com/a"))
+
+		v.reset("corpus")
+		assert.True(t, v.visit("corpus", "https://example.com/"))
+	}
+}
@@ -3,15 +3,21 @@ package web
 import (
 	"fmt"
 	"net/http"
-	"strings"
+	"net/url"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Jeffail/tunny"
 	"github.com/gocolly/colly/v2"
 	"github.com/l2cup/kids1/pkg/crawler"
 	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/index"
+	"github.com/l2cup/kids1/pkg/pool"
 	"github.com/l2cup/kids1/pkg/result"
 	"github.com/l2cup/kids1/pkg/runner"
+	"github.com/l2cup/kids1/pkg/text"
+	"github.com/pkg/errors"
 )
 
 type Config struct {
@@ -19,9 +25,34 @@ type Config struct {
 	Crawler           *crawler.Crawler
 	Dispatcher        *dispatcher.Dispatcher
 	ResultRetriever   result.Retriever
+	Index             index.Index
 	InitialHopCount   int
-	Keywords          []string
+	Counters          *crawler.CounterFactory
 	TTLMS             uint64
+	PoolSize          int
+	JobTimeoutMS      uint64
+	Tuner             *pool.TunerConfig
+	// Hosts is the politeness of the crawl, nil obeys robots.txt and sends
+	// one request at a time to a host.
+	Hosts   *HostsConfig
+	Visited *VisitedConfig
+	Text    *TextConfig
+	Content *ContentConfig
+	// Cache enables conditional requests for re-crawled pages, nil
+	// downloads every page in full.
+	Cache *CacheConfig
+	// Auth are the credential profiles, keyed by profile name.
+	Auth map[string]*AuthProfile
+	// Transport sets the proxies and certificates of every request, nil
+	// uses the environment's proxies and the system's certificates.
+	Transport *TransportConfig
+	// Guard blocks requests to internal addresses, nil allows them.
+	Guard *GuardConfig
+	// Fetch is the redirect and timeout policy crawls fall back to.
+	Fetch *FetchConfig
+	// CountErrorPages counts the words of pages answered with a non-2xx
+	// status and follows their links.
+	CountErrorPages bool
 }
 
 var _ crawler.WebCrawler = (*crawlerImplementation)(nil)
@@ -31,9 +62,24 @@ type crawlerImplementation struct {
 	*crawler.Crawler
 	dispatcher      *dispatcher.Dispatcher
 	resultRetriever result.Retriever
+	index           index.Index
 	pool            *tunny.Pool
+	tuner           *pool.Tuner
+	hosts           *hosts
+	visited         *visited
+	content         *content
+	cache           *cache
+	auth            *auth
+	transports      *transports
+	fetch           *FetchConfig
+	countErrorPages bool
+	seedClient      *http.Client
+	scopesMutex     sync.RWMutex
+	scopes          map[string]*scope
+	userAgent       string
+	jobTimeout      time.Duration
 	initialHopCount int
-	keywords        []string
+	counters        *crawler.CounterFactory
 	done            chan struct{}
 	ttl             time.Duration
 }
@@ -44,35 +90,249 @@ func NewCrawlerImplementation(c *Config) crawler.WebCrawler {
 		c.Crawler.Logger.Fatal("couldn't parse web page ttl time duration", "err", err, "duration", c.TTLMS)
 	}
 
+	jobTimeout, err := time.ParseDuration(fmt.Sprintf("%dms", c.JobTimeoutMS))
+	if err != nil {
+		c.Crawler.Logger.Fatal("couldn't parse web job timeout duration", "err", err, "duration", c.JobTimeoutMS)
+	}
+
+	if c.Fetch != nil && c.Fetch.Redirects != "" && !dispatcher.ValidRedirects(c.Fetch.Redirects) {
+		c.Crawler.Logger.Fatal("unknown web redirect policy", "redirects", c.Fetch.Redirects)
+	}
+	if c.Fetch != nil && c.Fetch.MaxRedirects < 0 {
+		c.Crawler.Logger.Fatal("negative web max redirects", "max_redirects", c.Fetch.MaxRedirects)
+	}
+
+	transports, err := newTransports(c.Transport, c.Guard)
+	if err != nil {
+		c.Crawler.Logger.Fatal("couldn't create web transport", "err", err)
+	}
+	defaultPolicy := newFetchPolicy(c.Fetch, 0, nil)
+	transport := transports.get(defaultPolicy.connectTimeout, defaultPolicy.readTimeout)
+	hosts := newHosts(c.Crawler.Logger, c.Hosts, transport)
+
 	ci := &crawlerImplementation{
 		Crawler:         c.Crawler,
 		dispatcher:      c.Dispatcher,
 		resultRetriever: c.ResultRetriever,
+		index:           c.Index,
 		initialHopCount: c.InitialHopCount,
-		keywords:        c.Keywords,
+		counters:        c.Counters,
 		done:            make(chan struct{}),
 		ttl:             ttl,
+		jobTimeout:      jobTimeout,
+		transports:      transports,
+		fetch:           c.Fetch,
+		countErrorPages: c.CountErrorPages,
+		hosts:           hosts,
+		visited:         newVisited(c.Visited),
+		scopes:          make(map[string]*scope),
+		seedClient:      &http.Client{Timeout: seedListTimeout, Transport: transport},
+		userAgent:       hosts.config.UserAgent,
+	}
+
+	ci.content, err = newContent(c.Content, c.Text)
+	if err != nil {
+		c.Crawler.Logger.Fatal("couldn't create web content handlers", "err", err)
 	}
 
+	ci.auth, err = newAuth(c.Auth)
+	if err != nil {
+		c.Crawler.Logger.Fatal("couldn't create web auth profiles", "err", err)
+	}
+	ci.seedClient.CheckRedirect = ci.checkRedirect(defaultPolicy)
+
 	c.RunnerRegistrator.Register(ci)
-	ci.pool = tunny.NewFunc(200, ci.crawlPage)
+	if c.Cache != nil {
+		ci.cache = newCache(c.Crawler.Logger, c.Cache)
+		c.RunnerRegistrator.Register(ci.cache)
+	}
+	ci.pool = tunny.NewFunc(c.PoolSize, ci.crawlPage)
+
+	if c.Tuner != nil {
+		c.Tuner.Resizer = ci
+		ci.tuner = pool.NewTuner(c.Tuner)
+	}
 	return ci
 }
 
-func (ci *crawlerImplementation) AddWebPage(url string) {
-	ci.resultRetriever.InitializeSummary(
-		dispatcher.WebJobType, url, 1, time.Now().Add(ci.ttl))
+func (ci *crawlerImplementation) PoolSize() int {
+	return ci.pool.GetSize()
+}
+
+func (ci *crawlerImplementation) SetPoolSize(size int) {
+	ci.pool.SetSize(size)
+}
+
+func (ci *crawlerImplementation) AddWebPage(url string, options *dispatcher.CorpusOptions) {
+	if !ci.startCorpus(url, options) {
+		return
+	}
+
+	seed := seedURL(url)
+	ci.visited.visit(url, seed)
+	ci.corpusScope(url).take()
 
 	ci.dispatcher.Push(&dispatcher.Job{
 		Type: dispatcher.WebJobType,
 		Payload: &dispatcher.WebCrawlerPayload{
 			CorpusName: url,
 			HopCount:   ci.initialHopCount,
-			URL:        url,
+			URL:        seed,
+			Options:    options,
 		},
 	})
 }
 
+func (ci *crawlerImplementation) AddSeedList(url, kind string, options *dispatcher.CorpusOptions) {
+	if !ci.startCorpus(url, options) {
+		return
+	}
+
+	go ci.crawlSeedList(url, kind, options)
+}
+
+// startCorpus initializes the summary, visited urls and scope of a web
+// corpus with a single job. It returns false if the corpus shouldn't be
+// crawled, because of an invalid scope or because it hasn't expired yet.
+func (ci *crawlerImplementation) startCorpus(url string, options *dispatcher.CorpusOptions) bool {
+	var sc *scope
+	if options != nil && options.Scope != nil {
+		seed, ok := parseURL(url)
+		if !ok {
+			ci.Logger.Error("can't scope a crawl of a non http url", "url", url)
+			return false
+		}
+
+		var err error
+		sc, err = newScope(seed, options.Scope)
+		if err != nil {
+			ci.Logger.Error("couldn't create crawl scope", "err", err, "url", url)
+			return false
+		}
+	}
+
+	created := ci.resultRetriever.InitializeSummary(
+		dispatcher.WebJobType, url, 1, time.Now().Add(ci.ttl), options.KeywordSetName())
+	if !created {
+		ci.Logger.Info("web corpus hasn't expired yet, not crawling it again", "corpus_name", url)
+		return false
+	}
+
+	ci.visited.reset(url)
+	// pages that left the site since the last crawl mustn't stay searchable.
+	if ci.index != nil {
+		ci.index.RemoveCorpus(dispatcher.WebJobType, url)
+	}
+
+	ci.scopesMutex.Lock()
+	ci.scopes[url] = sc
+	ci.scopesMutex.Unlock()
+	return true
+}
+
+// reportBlocked finishes the job of a url the guard refused to fetch, the
+// url is listed with the corpus' results.
+func (ci *crawlerImplementation) reportBlocked(corpusName, url string, blocked *blockedError, fetch *result.Fetch) {
+	ci.resultRetriever.UpdateSummary(&result.Results{
+		JobType:    dispatcher.WebJobType,
+		CorpusName: corpusName,
+		Source:     url,
+		Blocked:    blocked.Error(),
+		Fetch:      fetch,
+	})
+}
+
+// checkRedirect follows the redirects the policy allows, with the
+// credentials of the host redirected to.
+func (ci *crawlerImplementation) checkRedirect(policy *fetchPolicy) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if err := policy.checkRedirect(req, via); err != nil {
+			return err
+		}
+		ci.auth.redirect(req, via)
+		return nil
+	}
+}
+
+func (ci *crawlerImplementation) corpusScope(corpusName string) *scope {
+	defer ci.scopesMutex.RUnlock()
+	ci.scopesMutex.RLock()
+	return ci.scopes[corpusName]
+}
+
+// crawlSeedList pushes a job for every page of a sitemap or feed, following
+// nested sitemap indexes. The seed list is the corpus' single initial job,
+// it's finished once every page job is pushed.
+func (ci *crawlerImplementation) crawlSeedList(listURL, kind string, options *dispatcher.CorpusOptions) {
+	defer ci.resultRetriever.UpdateSummary(&result.Results{
+		JobType:    dispatcher.WebJobType,
+		CorpusName: listURL,
+	})
+
+	parent := &dispatcher.WebCrawlerPayload{
+		CorpusName: listURL,
+		HopCount:   ci.initialHopCount,
+		Options:    options,
+	}
+
+	sitemaps := []string{listURL}
+	for depth := 0; depth < maxSitemapDepth && len(sitemaps) > 0; depth++ {
+		var next []string
+		for _, sitemapURL := range sitemaps {
+			list, err := ci.fetchSeedList(sitemapURL)
+			if blocked, ok := blockedBy(err); ok && ci.resultRetriever.IncrementResultCount(dispatcher.WebJobType, listURL) == nil {
+				ci.Logger.Warn("blocked seed list", "err", blocked, "url", sitemapURL)
+				ci.reportBlocked(listURL, sitemapURL, blocked, nil)
+				continue
+			}
+			if err != nil {
+				ci.Logger.Error("couldn't get seed list", "err", err, "url", sitemapURL)
+				continue
+			}
+
+			if depth == 0 && list.Kind != kind {
+				ci.Logger.Error("seed list isn't of the expected kind", "url", listURL, "expected", kind, "kind", list.Kind)
+				return
+			}
+
+			listParent := *parent
+			listParent.URL = sitemapURL
+			for _, s := range list.Seeds {
+				if link, ok := parseURL(s.URL); ok {
+					ci.follow(&listParent, link, s.LastModified)
+				}
+			}
+			next = append(next, list.Sitemaps...)
+		}
+		sitemaps = next
+	}
+}
+
+func (ci *crawlerImplementation) fetchSeedList(listURL string) (*seedList, error) {
+	u, ok := parseURL(listURL)
+	if !ok {
+		return nil, errors.New("not an http url")
+	}
+
+	release, allowed := ci.hosts.acquire(u)
+	if !allowed {
+		return nil, errors.New("disallowed by robots.txt")
+	}
+	defer release()
+
+	header := make(http.Header)
+	if ci.userAgent != "" {
+		header.Set("User-Agent", ci.userAgent)
+	}
+	ci.auth.apply(u, header)
+
+	data, err := fetchSeedList(ci.seedClient, header, u.String(), ci.content.maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return parseSeedList(data)
+}
+
 func (ci *crawlerImplementation) Start() {
 	for {
 		select {
@@ -89,12 +349,55 @@ func (ci *crawlerImplementation) Stop() {
 	ci.done <- struct{}{}
 }
 
+// pageJob is a page that passed its host's robots.txt and crawl delay and
+// waits for a worker, it holds a slot of the host until it's done.
+type pageJob struct {
+	payload *dispatcher.WebCrawlerPayload
+	counter *text.Counter
+	cached  *cacheEntry
+	release func()
+	// state is pageWaiting until a worker takes the job or startJob gives
+	// up on it, whichever comes first finishes the job.
+	state int32
+}
+
+const (
+	pageWaiting int32 = iota
+	pageStarted
+	pageAbandoned
+)
+
 func (ci *crawlerImplementation) startJob(payload dispatcher.JobPayload) {
+	webPayload, ok := payload.(*dispatcher.WebCrawlerPayload)
+	if !ok {
+		ci.Logger.Error("payload not of type web crawler payload")
+		return
+	}
+
 	if ci.pool.GetSize() == 0 {
 		return
 	}
 
-	_, err := ci.pool.ProcessTimed(payload, 60*time.Second)
+	// the politeness wait happens here, before the page takes a worker, so it
+	// doesn't count against the job timeout.
+	job := ci.preparePage(webPayload)
+	if job == nil {
+		return
+	}
+
+	_, err := ci.pool.ProcessTimed(job, ci.jobTimeout)
+
+	if err != nil && atomic.CompareAndSwapInt32(&job.state, pageWaiting, pageAbandoned) {
+		job.release()
+		ci.Logger.Error("no worker took the web job", "err", err, "url", webPayload.URL)
+		ci.resultRetriever.UpdateSummary(&result.Results{
+			JobType:    dispatcher.WebJobType,
+			CorpusName: webPayload.CorpusName,
+			Source:     webPayload.URL,
+			Fetch:      newFetchRecord(webPayload, ci.initialHopCount).done(errors.Wrap(err, "no worker took the page")),
+		})
+		return
+	}
 
 	if err == tunny.ErrJobTimedOut {
 		ci.Logger.Error("goroutine timed out", "err", err)
@@ -107,91 +410,340 @@ func (ci *crawlerImplementation) startJob(payload dispatcher.JobPayload) {
 
 }
 
+// preparePage reuses the cached count of an unchanged page and waits for the
+// page's host, it returns nil if the job was finished without a fetch.
+func (ci *crawlerImplementation) preparePage(webPayload *dispatcher.WebCrawlerPayload) *pageJob {
+	counter, err := ci.counters.Counter(webPayload.CorpusName, webPayload.Options)
+	if err != nil {
+		ci.Logger.Error("couldn't get counter for corpus", "err", err, "corpus_name", webPayload.CorpusName)
+		ci.resultRetriever.UpdateSummary(&result.Results{
+			JobType:    dispatcher.WebJobType,
+			CorpusName: webPayload.CorpusName,
+		})
+		return nil
+	}
+
+	var cached *cacheEntry
+	if ci.cache != nil {
+		cached = ci.cache.get(cacheKey(webPayload.URL, counter))
+	}
+
+	if cached != nil && !webPayload.LastModified.IsZero() && cached.FetchedAt.After(webPayload.LastModified) {
+		ci.Logger.Debug("page unchanged since its last crawl, reusing cached count", "url", webPayload.URL)
+		ci.reuseCached(webPayload, cached.FinalURL, cached, nil)
+		return nil
+	}
+
+	job := &pageJob{payload: webPayload, counter: counter, cached: cached, release: func() {}}
+	if u, err := url.Parse(webPayload.URL); err == nil && u.Host != "" {
+		release, allowed := ci.hosts.acquire(u)
+		if !allowed {
+			ci.Logger.Debug("url disallowed by robots.txt", "url", webPayload.URL)
+			ci.resultRetriever.UpdateSummary(&result.Results{
+				JobType:    dispatcher.WebJobType,
+				CorpusName: webPayload.CorpusName,
+				Source:     webPayload.URL,
+				Fetch:      newFetchRecord(webPayload, ci.initialHopCount).done(errors.New("disallowed by robots.txt")),
+			})
+			return nil
+		}
+		job.release = release
+	}
+	return job
+}
+
 func (ci *crawlerImplementation) crawlPage(payload interface{}) interface{} {
-	webPayload, ok := payload.(*dispatcher.WebCrawlerPayload)
+	job, ok := payload.(*pageJob)
 	if !ok {
-		ci.Logger.Error("payload not of type web crawler payload")
+		ci.Logger.Error("payload not of type web page job")
+		return nil
+	}
+
+	if !atomic.CompareAndSwapInt32(&job.state, pageWaiting, pageStarted) {
+		return nil
 	}
+	defer job.release()
+	webPayload, counter, cached := job.payload, job.counter, job.cached
 
+	policy := newFetchPolicy(ci.fetch, ci.content.maxSize, webPayload.Options)
+	content := ci.content.withMaxSize(policy.maxBodySize)
+
+	start := time.Now()
 	c := colly.NewCollector()
+	c.WithTransport(ci.transports.get(policy.connectTimeout, policy.readTimeout))
+	if timeout := policy.requestTimeout(); timeout > 0 {
+		c.SetRequestTimeout(timeout)
+	}
+	c.SetRedirectHandler(ci.checkRedirect(policy))
+	if ci.userAgent != "" {
+		c.UserAgent = ci.userAgent
+	}
+	if size := content.bodySize(); size > 0 {
+		c.MaxBodySize = size
+	}
+	c.CheckHead = content.headCheck
+	c.OnResponseHeaders(content.onResponseHeaders)
+	c.OnRequest(func(r *colly.Request) { ci.auth.apply(r.URL, *r.Headers) })
+	// error pages and 304 Not Modified have to reach onScraped instead of
+	// failing the visit, so their status is recorded.
+	c.ParseHTTPErrorResponse = true
+
+	if ci.cache != nil {
+		c.OnRequest(func(r *colly.Request) {
+			if cached == nil || r.Method != http.MethodGet {
+				return
+			}
+			if cached.ETag != "" {
+				r.Headers.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				r.Headers.Set("If-Modified-Since", cached.LastModified)
+			}
+		})
+	}
 
-	if webPayload.HopCount > 0 {
-		c.OnHTML("a[href]", ci.onHtml(webPayload.CorpusName, webPayload.HopCount))
+	page := &page{record: newFetchRecord(webPayload, ci.initialHopCount)}
+	page.record.register(c)
+	if webPayload.HopCount > 0 || ci.cache != nil {
+		c.OnResponse(func(r *colly.Response) { page.base = r.Request.URL })
+		c.OnHTML("base[href]", page.onBase)
+		c.OnHTML("a[href]", ci.onHtml(webPayload, page))
 	}
 
-	c.OnScraped(ci.onScraped(webPayload.CorpusName, webPayload.HopCount))
+	c.OnResponse(func(r *colly.Response) {
+		ci.tuner.Observe(int64(len(r.Body)), time.Since(start))
+	})
+	c.OnScraped(ci.onScraped(webPayload, page, content, counter, cached))
+	// robots.txt is checked once per host by ci.hosts, not by every collector.
 	c.IgnoreRobotsTxt = true
 	err := c.Visit(webPayload.URL)
-	if err != nil {
+	if err == colly.ErrAbortedAfterHeaders {
+		ci.Logger.Debug("skipped url by its content type or size", "url", webPayload.URL)
+		ci.resultRetriever.UpdateSummary(&result.Results{
+			JobType:    dispatcher.WebJobType,
+			CorpusName: webPayload.CorpusName,
+			Source:     webPayload.URL,
+			Fetch:      page.record.skip(errors.New("content type or size")),
+		})
+	} else if blocked, ok := blockedBy(err); ok {
+		ci.Logger.Warn("blocked url", "err", blocked, "url", webPayload.URL)
+		ci.reportBlocked(webPayload.CorpusName, webPayload.URL, blocked, page.record.done(blocked))
+	} else if err != nil {
 		ci.Logger.Error("error visiting url", "err", err, "url", webPayload.URL)
 		ci.resultRetriever.UpdateSummary(&result.Results{
 			JobType:    dispatcher.WebJobType,
 			CorpusName: webPayload.CorpusName,
+			Source:     webPayload.URL,
+			Fetch:      page.record.done(err),
 		})
 	}
 
 	return nil
 }
 
-func (ci *crawlerImplementation) onScraped(jobName string, hopCount int) colly.ScrapedCallback {
+func (ci *crawlerImplementation) onScraped(
+	payload *dispatcher.WebCrawlerPayload,
+	page *page,
+	content *content,
+	counter *text.Counter,
+	cached *cacheEntry,
+) colly.ScrapedCallback {
+	jobName, hopCount := payload.CorpusName, payload.HopCount
 	return func(r *colly.Response) {
-		if r.StatusCode != http.StatusOK {
+		// the HEAD pre-check is scraped too, only the GET is counted.
+		if r.Request.Method == http.MethodHead {
+			return
+		}
+
+		if r.StatusCode == http.StatusNotModified && cached != nil {
+			ci.Logger.Debug("page not modified, reusing cached count", "url", r.Request.URL)
+			refreshed := *cached
+			refreshed.FinalURL = r.Request.URL.String()
+			refreshed.FetchedAt = time.Now()
+			ci.cache.put(cacheKey(payload.URL, counter), &refreshed)
+
+			ci.reuseCached(payload, refreshed.FinalURL, cached, page.record.done(nil))
+			return
+		}
+
+		if !successful(r.StatusCode) {
 			ci.Logger.Error("couldn't scrape web page and it's children",
 				"url", r.Request.URL,
 				"code", r.StatusCode,
 				"hops_left", hopCount)
+			if !ci.countErrorPages {
+				ci.resultRetriever.UpdateSummary(&result.Results{
+					JobType:    dispatcher.WebJobType,
+					CorpusName: jobName,
+					Source:     payload.URL,
+					Fetch:      page.record.done(errors.Errorf("status %d %s", r.StatusCode, http.StatusText(r.StatusCode))),
+				})
+				return
+			}
 		}
 
-		results := make(map[string]int64)
-		for _, word := range ci.keywords {
-			results[word] = 0
+		body, err := content.text(r)
+		if err != nil {
+			ci.Logger.Debug("skipped page", "err", err, "url", r.Request.URL)
+			var fetch *result.Fetch
+			if isSkipped(err) {
+				fetch = page.record.skip(err)
+			} else {
+				fetch = page.record.done(err)
+			}
+			ci.resultRetriever.UpdateSummary(&result.Results{
+				JobType:    dispatcher.WebJobType,
+				CorpusName: jobName,
+				Source:     payload.URL,
+				Fetch:      fetch,
+			})
+			return
 		}
 
-		words := strings.Fields(string(r.Body))
-		for _, w := range words {
-			if result, ok := results[w]; ok {
-				results[w] = result + 1
+		count := counter.Count(body)
+
+		if ci.cache != nil && successful(r.StatusCode) {
+			etag, lastModified := r.Headers.Get("ETag"), r.Headers.Get("Last-Modified")
+			if etag != "" || lastModified != "" {
+				ci.cache.put(cacheKey(payload.URL, counter), &cacheEntry{
+					ETag:         etag,
+					LastModified: lastModified,
+					Count:        count,
+					Links:        page.links,
+					FinalURL:     r.Request.URL.String(),
+					FetchedAt:    time.Now(),
+				})
 			}
 		}
 
-		ci.Logger.Debug("web job finished, updating summary", "results", results)
+		ci.updateSummary(jobName, payload.URL, r.Request.URL.String(), count, page.record.done(nil))
+	}
+}
 
-		ci.resultRetriever.UpdateSummary(&result.Results{
-			CorpusName: jobName,
+// reuseCached counts a page that didn't change since it was cached, without
+// downloading it again.
+func (ci *crawlerImplementation) reuseCached(
+	payload *dispatcher.WebCrawlerPayload,
+	finalURL string,
+	cached *cacheEntry,
+	fetch *result.Fetch,
+) {
+	if payload.HopCount > 0 {
+		for _, link := range cached.Links {
+			if u, ok := parseURL(link); ok {
+				ci.follow(payload, u, time.Time{})
+			}
+		}
+	}
+	ci.updateSummary(payload.CorpusName, payload.URL, finalURL, cached.Count, fetch)
+}
+
+// updateSummary adds the count of the source url, finalURL is where its
+// redirects ended and fetch how it was fetched, nil if it wasn't.
+func (ci *crawlerImplementation) updateSummary(jobName, source, finalURL string, count *text.Count, fetch *result.Fetch) {
+	ci.Logger.Debug("web job finished, updating summary", "results", count.Results)
+
+	if ci.index != nil {
+		ci.index.Add(&index.Document{
 			JobType:    dispatcher.WebJobType,
-			Results:    results,
+			CorpusName: jobName,
+			Source:     source,
+			Words:      count.Words,
 		})
 	}
+
+	ci.resultRetriever.UpdateSummary(&result.Results{
+		CorpusName: jobName,
+		JobType:    dispatcher.WebJobType,
+		Source:     source,
+		FinalURL:   finalURL,
+		Tokens:     count.Tokens,
+		Language:   count.Language,
+		Results:    count.Results,
+		Matches:    count.Matches,
+		Analysis:   count.Analysis,
+		Vocabulary: count.Vocabulary,
+		Fetch:      fetch,
+	})
 }
 
-func (ci *crawlerImplementation) onHtml(jobName string, hopCount int) colly.HTMLCallback {
-	return func(e *colly.HTMLElement) {
-		url := e.Attr("href")
+// page tracks the url the links of a crawled page are resolved against,
+// the links found so far and how the page was fetched.
+type page struct {
+	base   *url.URL
+	links  []string
+	record *fetchRecord
+}
 
-		if strings.HasPrefix(url, "/") {
-			url = "http://" + e.Request.URL.Host + url
-		}
+// successful reports whether the status is 2xx, only those pages are
+// counted unless error pages are.
+func successful(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
+}
 
-		payload := &dispatcher.WebCrawlerPayload{
-			CorpusName: jobName,
-			HopCount:   hopCount - 1,
-			URL:        url,
-		}
+// onBase switches the base url to the first <base href> of the page.
+func (p *page) onBase(e *colly.HTMLElement) {
+	if e.Index > 0 {
+		return
+	}
+	if base, err := e.Request.URL.Parse(e.Attr("href")); err == nil {
+		p.base = base
+	}
+}
 
-		job := &dispatcher.Job{
-			Type:    dispatcher.WebJobType,
-			Payload: payload,
+func (ci *crawlerImplementation) onHtml(parent *dispatcher.WebCrawlerPayload, page *page) colly.HTMLCallback {
+	return func(e *colly.HTMLElement) {
+		if !successful(e.Response.StatusCode) && !ci.countErrorPages {
+			return
 		}
 
-		err := ci.resultRetriever.IncrementResultCount(dispatcher.WebJobType, jobName)
-		if err != nil {
-			ci.Logger.Error("couldn't increment result count for web jobs",
-				"err", err,
-				"job_name", jobName,
-			)
+		link, ok := resolveURL(page.base, e.Attr("href"))
+		if !ok {
 			return
 		}
 
-		ci.dispatcher.Push(job)
+		if ci.cache != nil {
+			page.links = append(page.links, link.String())
+		}
+		if parent.HopCount > 0 {
+			ci.follow(parent, link, time.Time{})
+		}
 	}
 }
+
+// follow pushes a job for a link of the parent page if it's within the
+// corpus scope and wasn't crawled yet.
+func (ci *crawlerImplementation) follow(parent *dispatcher.WebCrawlerPayload, link *url.URL, lastModified time.Time) {
+	jobName := parent.CorpusName
+	sc := ci.corpusScope(jobName)
+
+	if !sc.allows(link) || !ci.visited.visit(jobName, link.String()) || !sc.take() {
+		return
+	}
+
+	payload := &dispatcher.WebCrawlerPayload{
+		CorpusName: jobName,
+		HopCount:   parent.HopCount - 1,
+		URL:        link.String(),
+		Options:    parent.Options,
+
+		LastModified: lastModified,
+		Parent:       parent.URL,
+	}
+
+	job := &dispatcher.Job{
+		Type:    dispatcher.WebJobType,
+		Payload: payload,
+	}
+
+	err := ci.resultRetriever.IncrementResultCount(dispatcher.WebJobType, jobName)
+	if err != nil {
+		ci.Logger.Error("couldn't increment result count for web jobs",
+			"err", err,
+			"job_name", jobName,
+		)
+		return
+	}
+
+	ci.dispatcher.Push(job)
+}
@@ -1,5 +1,7 @@
 package dispatcher
 
+import "time"
+
 type JobType string
 type JobPayload = interface{}
 
@@ -14,20 +16,101 @@ type Job struct {
 	Payload JobPayload
 }
 
+// CorpusOptions are the per corpus settings given when a directory or web
+// page is added, empty fields fall back to the system config.
+type CorpusOptions struct {
+	Stemmer    string
+	KeywordSet string
+	// Scope limits the pages a web crawl follows links to, nil follows every
+	// link.
+	Scope *WebScope
+	// Fetch overrides how a web crawl's pages are fetched, nil uses the
+	// system config.
+	Fetch *WebFetch
+}
+
+// WebScope restricts a web crawl to the pages matching all of its set
+// fields.
+type WebScope struct {
+	// SameHost keeps the crawl on the host of the seed url.
+	SameHost bool
+	// SameDomain keeps the crawl on the registrable domain of the seed url,
+	// e.g. example.co.uk for docs.example.co.uk.
+	SameDomain bool
+	PathPrefix string
+	// Allow and Deny are regular expressions matched against the whole url,
+	// a url must match one of Allow, if given, and none of Deny.
+	Allow []string
+	Deny  []string
+	// MaxPages caps the pages fetched for the corpus, 0 means no limit.
+	MaxPages int
+}
+
+// Redirect policies of a web crawl, they decide where a redirect may lead
+// relative to the url that was requested.
+const (
+	AnyRedirects        = "any"
+	SameHostRedirects   = "same-host"
+	SameDomainRedirects = "same-domain"
+	NoRedirects         = "none"
+)
+
+// ValidRedirects reports whether policy is one of the redirect policies.
+func ValidRedirects(policy string) bool {
+	switch policy {
+	case AnyRedirects, SameHostRedirects, SameDomainRedirects, NoRedirects:
+		return true
+	}
+	return false
+}
+
+// WebFetch is the redirect, timeout and size policy of a web crawl, zero
+// fields fall back to the system config.
+type WebFetch struct {
+	MaxRedirects int
+	// Redirects is one of the redirect policies.
+	Redirects string
+	// ConnectTimeoutMS bounds connecting to a host, ReadTimeoutMS bounds
+	// receiving the whole response once connected.
+	ConnectTimeoutMS uint64
+	ReadTimeoutMS    uint64
+	// MaxBodySize is the largest page in bytes that's downloaded.
+	MaxBodySize int
+}
+
+// DefaultKeywordSet names the keywords of the system config.
+const DefaultKeywordSet = "default"
+
+func (o *CorpusOptions) KeywordSetName() string {
+	if o == nil || o.KeywordSet == "" {
+		return DefaultKeywordSet
+	}
+	return o.KeywordSet
+}
+
 type DirectoryCrawlerPayload struct {
 	CorpusName string
 	Path       string
 	Size       int64
+	Options    *CorpusOptions
 }
 
 type FileCrawlerPayload struct {
 	CorpusName string
 	Path       string
 	Size       int64
+	Options    *CorpusOptions
 }
 
 type WebCrawlerPayload struct {
 	CorpusName string
 	HopCount   int
 	URL        string
+	Options    *CorpusOptions
+	// LastModified is when a sitemap or feed says the page last changed,
+	// zero if it's unknown.
+	LastModified time.Time
+	// Parent is the url of the page or seed list the url was found on,
+	// empty for the seed url.
+	Parent string
 }
@@ -0,0 +1,338 @@
+package index
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/log"
+	"github.com/l2cup/kids1/pkg/persist"
+	"github.com/l2cup/kids1/pkg/runner"
+)
+
+const (
+	indexFileName = "index.gob"
+
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+type Index interface {
+	runner.Runner
+
+	Add(document *Document)
+	// RemoveCorpus drops the documents of the corpus, or of every corpus of
+	// the job type when corpusName is empty.
+	RemoveCorpus(jobType dispatcher.JobType, corpusName string)
+	Search(query string, corpusName string, limit int) ([]Hit, error)
+}
+
+type Config struct {
+	Logger            *log.Logger
+	RunnerRegistrator runner.Registrator
+	// Path is the directory the index is persisted to, empty keeps the index
+	// in memory only.
+	Path            string
+	FlushIntervalMS uint64
+}
+
+type Document struct {
+	JobType    dispatcher.JobType
+	CorpusName string
+	Source     string
+	// Words are the normalized words of the document, their index is used as
+	// the word position.
+	Words []string
+}
+
+type Hit struct {
+	JobType    dispatcher.JobType
+	CorpusName string
+	Source     string
+	Score      float64
+}
+
+type documentInfo struct {
+	JobType    dispatcher.JobType
+	CorpusName string
+	Source     string
+	Length     int
+	Terms      []string
+}
+
+// snapshot is the on-disk representation of the index.
+type snapshot struct {
+	NextID    int
+	Documents map[int]*documentInfo
+	Keys      map[string]int
+	Postings  map[string]map[int][]int
+}
+
+var _ Index = (*indexImplementation)(nil)
+var _ runner.Runner = (*indexImplementation)(nil)
+
+type indexImplementation struct {
+	logger        *log.Logger
+	path          string
+	flushInterval time.Duration
+
+	mutex       sync.RWMutex
+	changes     persist.Changes
+	totalLength int
+	snapshot
+
+	done chan struct{}
+}
+
+func New(c *Config) Index {
+	flushInterval, err := time.ParseDuration(fmt.Sprintf("%dms", c.FlushIntervalMS))
+	if err != nil {
+		c.Logger.Fatal("couldn't parse index flush interval duration", "err", err, "duration", c.FlushIntervalMS)
+	}
+
+	ii := &indexImplementation{
+		logger:        c.Logger,
+		path:          c.Path,
+		flushInterval: flushInterval,
+		snapshot: snapshot{
+			Documents: make(map[int]*documentInfo),
+			Keys:      make(map[string]int),
+			Postings:  make(map[string]map[int][]int),
+		},
+		done: make(chan struct{}),
+	}
+
+	if err := ii.load(); err != nil {
+		ii.logger.Error("[index] couldn't load index, starting empty", "err", err, "path", ii.path)
+	}
+
+	c.RunnerRegistrator.Register(ii)
+	return ii
+}
+
+func (ii *indexImplementation) Start() {
+	if ii.path == "" {
+		<-ii.done
+		return
+	}
+
+	ticker := time.NewTicker(ii.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ii.flush()
+		case <-ii.done:
+			ii.flush()
+			return
+		}
+	}
+}
+
+func (ii *indexImplementation) Stop() {
+	ii.done <- struct{}{}
+}
+
+func (ii *indexImplementation) Add(document *Document) {
+	defer ii.mutex.Unlock()
+	ii.mutex.Lock()
+
+	key := documentKey(document.JobType, document.CorpusName, document.Source)
+	if id, ok := ii.Keys[key]; ok {
+		ii.remove(id)
+	}
+
+	id := ii.NextID
+	ii.NextID++
+
+	positions := make(map[string][]int)
+	for i, word := range document.Words {
+		positions[word] = append(positions[word], i)
+	}
+
+	terms := make([]string, 0, len(positions))
+	for term, p := range positions {
+		postings, ok := ii.Postings[term]
+		if !ok {
+			postings = make(map[int][]int)
+			ii.Postings[term] = postings
+		}
+		postings[id] = p
+		terms = append(terms, term)
+	}
+
+	ii.Documents[id] = &documentInfo{
+		JobType:    document.JobType,
+		CorpusName: document.CorpusName,
+		Source:     document.Source,
+		Length:     len(document.Words),
+		Terms:      terms,
+	}
+	ii.Keys[key] = id
+	ii.totalLength += len(document.Words)
+	ii.changes.Change()
+}
+
+func (ii *indexImplementation) RemoveCorpus(jobType dispatcher.JobType, corpusName string) {
+	defer ii.mutex.Unlock()
+	ii.mutex.Lock()
+
+	for id, doc := range ii.Documents {
+		if doc.JobType != jobType || (corpusName != "" && doc.CorpusName != corpusName) {
+			continue
+		}
+		ii.remove(id)
+		ii.changes.Change()
+	}
+}
+
+func (ii *indexImplementation) remove(id int) {
+	doc := ii.Documents[id]
+	for _, term := range doc.Terms {
+		delete(ii.Postings[term], id)
+		if len(ii.Postings[term]) == 0 {
+			delete(ii.Postings, term)
+		}
+	}
+
+	ii.totalLength -= doc.Length
+	delete(ii.Keys, documentKey(doc.JobType, doc.CorpusName, doc.Source))
+	delete(ii.Documents, id)
+}
+
+func (ii *indexImplementation) Search(query string, corpusName string, limit int) ([]Hit, error) {
+	q, err := parseQuery(query)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't parse query")
+	}
+
+	defer ii.mutex.RUnlock()
+	ii.mutex.RLock()
+
+	matched := q.evaluate(ii)
+	terms := q.terms(nil)
+
+	hits := make([]Hit, 0, len(matched))
+	for id := range matched {
+		doc := ii.Documents[id]
+		if corpusName != "" && doc.CorpusName != corpusName {
+			continue
+		}
+
+		hits = append(hits, Hit{
+			JobType:    doc.JobType,
+			CorpusName: doc.CorpusName,
+			Source:     doc.Source,
+			Score:      ii.score(id, terms),
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score == hits[j].Score {
+			return hits[i].Source < hits[j].Source
+		}
+		return hits[i].Score > hits[j].Score
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// score ranks a document with bm25 over the positive terms of the query.
+func (ii *indexImplementation) score(id int, terms []string) float64 {
+	n := float64(len(ii.Documents))
+	avgLength := float64(ii.totalLength) / n
+	length := float64(ii.Documents[id].Length)
+
+	score := 0.0
+	for _, term := range terms {
+		postings := ii.Postings[term]
+		tf := float64(len(postings[id]))
+		if tf == 0 {
+			continue
+		}
+
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*length/avgLength))
+	}
+	return score
+}
+
+func (ii *indexImplementation) all() map[int]struct{} {
+	ids := make(map[int]struct{}, len(ii.Documents))
+	for id := range ii.Documents {
+		ids[id] = struct{}{}
+	}
+	return ids
+}
+
+func (ii *indexImplementation) flush() {
+	if err := persist.Flush(&ii.mutex, &ii.changes, ii.save); err != nil {
+		ii.logger.Error("[index] couldn't persist index", "err", err, "path", ii.path)
+	}
+}
+
+func (ii *indexImplementation) save() error {
+	if err := os.MkdirAll(ii.path, 0755); err != nil {
+		return errors.Wrap(err, "couldn't create index directory")
+	}
+
+	tmp, err := os.CreateTemp(ii.path, indexFileName+".*")
+	if err != nil {
+		return errors.Wrap(err, "couldn't create index file")
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(&ii.snapshot); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "couldn't encode index")
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "couldn't write index file")
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(ii.path, indexFileName))
+}
+
+func (ii *indexImplementation) load() error {
+	if ii.path == "" {
+		return nil
+	}
+
+	file, err := os.Open(filepath.Join(ii.path, indexFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	s := snapshot{}
+	if err := gob.NewDecoder(file).Decode(&s); err != nil {
+		return errors.Wrap(err, "couldn't decode index")
+	}
+
+	ii.snapshot = s
+	for _, doc := range s.Documents {
+		ii.totalLength += doc.Length
+	}
+	return nil
+}
+
+func documentKey(jobType dispatcher.JobType, corpusName, source string) string {
+	return string(jobType) + "\x00" + corpusName + "\x00" + source
+}
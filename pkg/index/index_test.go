@@ -0,0 +1,113 @@
+package index
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/testutil"
+)
+
+// addDocuments adds the documents the searches are checked against.
+func addDocuments(ii Index) {
+	for source, body := range map[string]string{
+		"a": "the quick brown fox",
+		"b": "the lazy brown dog",
+		"c": "quick quick dog",
+	} {
+		ii.Add(&Document{
+			JobType:    dispatcher.FileJobType,
+			CorpusName: "corpus",
+			Source:     source,
+			Words:      strings.Fields(body),
+		})
+	}
+}
+
+func sources(hits []Hit) []string {
+	s := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		s = append(s, hit.Source)
+	}
+	return s
+}
+
+func TestSearch(t *testing.T) {
+	ii := New(&Config{Logger: testutil.Logger(t), RunnerRegistrator: testutil.Registrator{}, FlushIntervalMS: 1000})
+	addDocuments(ii)
+
+	for query, expected := range map[string][]string{
+		"quick":                      {"c", "a"},
+		"brown AND dog":              {"b"},
+		"brown dog":                  {"b"},
+		"fox OR lazy":                {"a", "b"},
+		"dog NOT lazy":               {"c"},
+		`"brown fox"`:                {"a"},
+		`"brown dog" OR "quick dog"`: {"b", "c"},
+		"(fox OR dog) AND NOT quick": {"b"},
+	} {
+		hits, err := ii.Search(query, "", 0)
+		assert.NoError(t, err, query)
+		assert.ElementsMatch(t, expected, sources(hits), query)
+	}
+
+	hits, err := ii.Search("quick", "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "c", hits[0].Source)
+
+	_, err = ii.Search(`"unterminated`, "", 0)
+	assert.Error(t, err)
+}
+
+func TestSearchTransliterated(t *testing.T) {
+	ii := New(&Config{Logger: testutil.Logger(t), RunnerRegistrator: testutil.Registrator{}, FlushIntervalMS: 1000})
+	addDocuments(ii)
+	ii.Add(&Document{JobType: dispatcher.WebJobType, CorpusName: "latin", Source: "d", Words: []string{"brza", "lisica"}})
+	ii.Add(&Document{JobType: dispatcher.WebJobType, CorpusName: "cyrillic", Source: "e", Words: []string{"брза", "лисица"}})
+
+	for query, expected := range map[string][]string{
+		"лисица":        {"d", "e"},
+		`"брза лисица"`: {"d", "e"},
+		"lisica":        {"d"},
+		"fox NOT брза":  {"a"},
+	} {
+		hits, err := ii.Search(query, "", 0)
+		assert.NoError(t, err, query)
+		assert.ElementsMatch(t, expected, sources(hits), query)
+	}
+}
+
+func TestIndexPersists(t *testing.T) {
+	dir := t.TempDir()
+	ii := New(&Config{Logger: testutil.Logger(t), RunnerRegistrator: testutil.Registrator{}, Path: dir, FlushIntervalMS: 1000})
+	addDocuments(ii)
+	ii.(*indexImplementation).flush()
+
+	reloaded := New(&Config{Logger: testutil.Logger(t), RunnerRegistrator: testutil.Registrator{}, Path: dir, FlushIntervalMS: 1000})
+	hits, err := reloaded.Search(`"quick brown"`, "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, sources(hits))
+}
+
+func TestRemoveCorpus(t *testing.T) {
+	ii := New(&Config{Logger: testutil.Logger(t), RunnerRegistrator: testutil.Registrator{}, FlushIntervalMS: 1000})
+	addDocuments(ii)
+	ii.Add(&Document{JobType: dispatcher.FileJobType, CorpusName: "other", Source: "d", Words: []string{"quick", "fox"}})
+	ii.Add(&Document{JobType: dispatcher.WebJobType, CorpusName: "corpus", Source: "e", Words: []string{"quick"}})
+
+	ii.RemoveCorpus(dispatcher.FileJobType, "corpus")
+	hits, err := ii.Search("quick", "", 0)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"d", "e"}, sources(hits))
+
+	hits, err = ii.Search("brown OR dog", "", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+
+	ii.RemoveCorpus(dispatcher.FileJobType, "")
+	hits, err = ii.Search("quick", "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"e"}, sources(hits))
+}
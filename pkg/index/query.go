@@ -0,0 +1,353 @@
+package index
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/l2cup/kids1/pkg/text"
+	"github.com/l2cup/kids1/pkg/text/translit"
+)
+
+type queryNode interface {
+	evaluate(ii *indexImplementation) map[int]struct{}
+	// terms appends the terms that contribute to the score of a match.
+	terms(acc []string) []string
+}
+
+type termNode struct {
+	term string
+}
+
+type phraseNode struct {
+	words []string
+}
+
+type notNode struct {
+	child queryNode
+}
+
+type andNode struct {
+	children []queryNode
+}
+
+type orNode struct {
+	children []queryNode
+}
+
+func (n *termNode) evaluate(ii *indexImplementation) map[int]struct{} {
+	ids := make(map[int]struct{}, len(ii.Postings[n.term]))
+	for id := range ii.Postings[n.term] {
+		ids[id] = struct{}{}
+	}
+	return ids
+}
+
+func (n *termNode) terms(acc []string) []string {
+	return append(acc, n.term)
+}
+
+func (n *phraseNode) evaluate(ii *indexImplementation) map[int]struct{} {
+	ids := make(map[int]struct{})
+
+	first := ii.Postings[n.words[0]]
+	for id, positions := range first {
+		for _, p := range positions {
+			if n.matchesAt(ii, id, p) {
+				ids[id] = struct{}{}
+				break
+			}
+		}
+	}
+	return ids
+}
+
+func (n *phraseNode) matchesAt(ii *indexImplementation, id, position int) bool {
+	for i, word := range n.words[1:] {
+		if !containsPosition(ii.Postings[word][id], position+i+1) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsPosition(positions []int, position int) bool {
+	// positions are appended in order while indexing so they're sorted.
+	lo, hi := 0, len(positions)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if positions[mid] < position {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo < len(positions) && positions[lo] == position
+}
+
+func (n *phraseNode) terms(acc []string) []string {
+	return append(acc, n.words...)
+}
+
+func (n *notNode) evaluate(ii *indexImplementation) map[int]struct{} {
+	excluded := n.child.evaluate(ii)
+	ids := ii.all()
+	for id := range excluded {
+		delete(ids, id)
+	}
+	return ids
+}
+
+func (n *notNode) terms(acc []string) []string {
+	return acc
+}
+
+func (n *andNode) evaluate(ii *indexImplementation) map[int]struct{} {
+	ids := n.children[0].evaluate(ii)
+	for _, child := range n.children[1:] {
+		other := child.evaluate(ii)
+		for id := range ids {
+			if _, ok := other[id]; !ok {
+				delete(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+func (n *andNode) terms(acc []string) []string {
+	for _, child := range n.children {
+		acc = child.terms(acc)
+	}
+	return acc
+}
+
+func (n *orNode) evaluate(ii *indexImplementation) map[int]struct{} {
+	ids := make(map[int]struct{})
+	for _, child := range n.children {
+		for id := range child.evaluate(ii) {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids
+}
+
+func (n *orNode) terms(acc []string) []string {
+	for _, child := range n.children {
+		acc = child.terms(acc)
+	}
+	return acc
+}
+
+type queryToken struct {
+	value  string
+	phrase bool
+}
+
+// queryParser parses queries of the form
+//
+//	query   = or
+//	or      = and { "OR" and }
+//	and     = not { ["AND"] not }
+//	not     = "NOT" not | primary
+//	primary = word | '"' words '"' | "(" or ")"
+type queryParser struct {
+	tokens   []queryToken
+	position int
+}
+
+func parseQuery(query string) (queryNode, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty query")
+	}
+
+	p := &queryParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.position < len(p.tokens) {
+		return nil, errors.Errorf("unexpected %q", p.tokens[p.position].value)
+	}
+	return node, nil
+}
+
+func lexQuery(query string) ([]queryToken, error) {
+	tokens := make([]queryToken, 0)
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, queryToken{value: string(r)})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.New("unterminated phrase")
+			}
+			tokens = append(tokens, queryToken{value: string(runes[i+1 : end]), phrase: true})
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' && runes[end] != '"' {
+				end++
+			}
+			tokens = append(tokens, queryToken{value: string(runes[i:end])})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.position < len(p.tokens) {
+		return p.tokens[p.position], true
+	}
+	return queryToken{}, false
+}
+
+func (p *queryParser) isOperator(value string) bool {
+	t, ok := p.peek()
+	return ok && !t.phrase && t.value == value
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []queryNode{node}
+	for p.isOperator("OR") {
+		p.position++
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &orNode{children: children}, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	node, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []queryNode{node}
+	for {
+		t, ok := p.peek()
+		if !ok || p.isOperator("OR") || (!t.phrase && t.value == ")") {
+			break
+		}
+		if p.isOperator("AND") {
+			p.position++
+		}
+
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return &andNode{children: children}, nil
+}
+
+func (p *queryParser) parseNot() (queryNode, error) {
+	if p.isOperator("NOT") {
+		p.position++
+		child, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, errors.New("unexpected end of query")
+	}
+	p.position++
+
+	if t.phrase {
+		words := make([]string, 0)
+		for _, field := range strings.Fields(t.value) {
+			if word := text.Normalize(field); word != "" {
+				words = append(words, word)
+			}
+		}
+		if len(words) == 0 {
+			return nil, errors.New("empty phrase")
+		}
+		return withLatin(words), nil
+	}
+
+	switch t.value {
+	case "(":
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isOperator(")") {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		p.position++
+		return node, nil
+	case ")", "AND", "OR":
+		return nil, errors.Errorf("unexpected %q", t.value)
+	}
+
+	term := text.Normalize(t.value)
+	if term == "" {
+		return nil, errors.Errorf("%q isn't a searchable term", t.value)
+	}
+	return withLatin([]string{term}), nil
+}
+
+// withLatin matches the words as written or transliterated to Latin, corpora
+// counted with transliteration index their Cyrillic words in Latin.
+func withLatin(words []string) queryNode {
+	latin := make([]string, len(words))
+	transliterated := false
+	for i, word := range words {
+		latin[i] = translit.Latin(word)
+		transliterated = transliterated || latin[i] != word
+	}
+
+	if !transliterated {
+		return wordsNode(words)
+	}
+	return &orNode{children: []queryNode{wordsNode(words), wordsNode(latin)}}
+}
+
+func wordsNode(words []string) queryNode {
+	if len(words) == 1 {
+		return &termNode{term: words[0]}
+	}
+	return &phraseNode{words: words}
+}
@@ -0,0 +1,46 @@
+package persist
+
+import "sync"
+
+// Changes counts the changes made to a structure persisted in the background,
+// so a flush only marks as saved the changes its save has seen. Change is
+// called with the structure's write lock held.
+type Changes struct {
+	made  uint64
+	saved uint64
+}
+
+// Change records a change that still has to be saved.
+func (c *Changes) Change() {
+	c.made++
+}
+
+// Pending reports whether there are changes that haven't been saved yet.
+func (c *Changes) Pending() bool {
+	return c.made != c.saved
+}
+
+// Flush runs save under the read lock when there are pending changes. The
+// changes made after save started stay pending for the next flush.
+func Flush(mutex *sync.RWMutex, c *Changes, save func() error) error {
+	mutex.RLock()
+	if !c.Pending() {
+		mutex.RUnlock()
+		return nil
+	}
+
+	made := c.made
+	err := save()
+	mutex.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	mutex.Lock()
+	if made > c.saved {
+		c.saved = made
+	}
+	mutex.Unlock()
+	return nil
+}
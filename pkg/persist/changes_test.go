@@ -0,0 +1,49 @@
+package persist
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlush(t *testing.T) {
+	mutex := sync.RWMutex{}
+	c := &Changes{}
+
+	saves := 0
+	save := func() error {
+		saves++
+		return nil
+	}
+
+	assert.NoError(t, Flush(&mutex, c, save))
+	assert.Equal(t, 0, saves)
+
+	c.Change()
+	assert.NoError(t, Flush(&mutex, c, save))
+	assert.Equal(t, 1, saves)
+	assert.False(t, c.Pending())
+
+	c.Change()
+	assert.Error(t, Flush(&mutex, c, func() error { return errors.New("disk full") }))
+	assert.True(t, c.Pending())
+}
+
+func TestFlushKeepsLaterChanges(t *testing.T) {
+	mutex := sync.RWMutex{}
+	c := &Changes{}
+	c.Change()
+
+	// A change counted after the save started isn't part of it and stays
+	// pending, the test is single goroutined so it doesn't need the lock.
+	assert.NoError(t, Flush(&mutex, c, func() error {
+		c.Change()
+		return nil
+	}))
+	assert.True(t, c.Pending())
+
+	assert.NoError(t, Flush(&mutex, c, func() error { return nil }))
+	assert.False(t, c.Pending())
+}
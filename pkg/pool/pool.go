@@ -0,0 +1,6 @@
+package pool
+
+type Resizer interface {
+	PoolSize() int
+	SetPoolSize(size int)
+}
@@ -0,0 +1,163 @@
+package pool
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/l2cup/kids1/pkg/log"
+	"github.com/l2cup/kids1/pkg/runner"
+)
+
+const (
+	// throughputTolerance is the relative throughput change treated as noise.
+	throughputTolerance = 0.05
+	// latencyGrowthLimit is how much the average job latency may grow between
+	// two windows with flat throughput before the tuner backs off.
+	latencyGrowthLimit = 1.5
+)
+
+type TunerConfig struct {
+	Logger  *log.Logger
+	Name    string
+	Resizer Resizer
+	MinSize int
+	MaxSize int
+	// Step is the number of workers added or removed per interval, when zero
+	// the pool grows and shrinks by a tenth of its current size.
+	Step              int
+	Interval          time.Duration
+	RunnerRegistrator runner.Registrator
+}
+
+var _ runner.Runner = (*Tuner)(nil)
+
+// Tuner adjusts the size of a pool by hill climbing on the throughput
+// observed in each interval, backing off when latency grows without any
+// throughput gain.
+type Tuner struct {
+	logger   *log.Logger
+	name     string
+	resizer  Resizer
+	minSize  int
+	maxSize  int
+	step     int
+	interval time.Duration
+
+	bytes     int64
+	jobs      int64
+	latencyNS int64
+
+	lastThroughput float64
+	lastLatency    time.Duration
+	direction      int
+
+	done chan struct{}
+}
+
+func NewTuner(c *TunerConfig) *Tuner {
+	t := &Tuner{
+		logger:    c.Logger,
+		name:      c.Name,
+		resizer:   c.Resizer,
+		minSize:   c.MinSize,
+		maxSize:   c.MaxSize,
+		step:      c.Step,
+		interval:  c.Interval,
+		direction: 1,
+		done:      make(chan struct{}),
+	}
+
+	if c.RunnerRegistrator != nil {
+		c.RunnerRegistrator.Register(t)
+	}
+	return t
+}
+
+func (t *Tuner) Start() {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.adjust()
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *Tuner) Stop() {
+	t.done <- struct{}{}
+}
+
+// Observe records a finished job. Bytes is the amount of data the job read
+// from disk or network, zero if the job doesn't move any data.
+func (t *Tuner) Observe(bytes int64, latency time.Duration) {
+	if t == nil {
+		return
+	}
+	atomic.AddInt64(&t.bytes, bytes)
+	atomic.AddInt64(&t.jobs, 1)
+	atomic.AddInt64(&t.latencyNS, int64(latency))
+}
+
+func (t *Tuner) adjust() {
+	bytes := atomic.SwapInt64(&t.bytes, 0)
+	jobs := atomic.SwapInt64(&t.jobs, 0)
+	latencyNS := atomic.SwapInt64(&t.latencyNS, 0)
+
+	if jobs == 0 {
+		return
+	}
+
+	work := float64(bytes)
+	if bytes == 0 {
+		work = float64(jobs)
+	}
+
+	throughput := work / t.interval.Seconds()
+	latency := time.Duration(latencyNS / jobs)
+
+	switch {
+	case t.lastThroughput == 0:
+	case throughput < t.lastThroughput*(1-throughputTolerance):
+		t.direction = -t.direction
+	case throughput <= t.lastThroughput*(1+throughputTolerance) &&
+		float64(latency) > float64(t.lastLatency)*latencyGrowthLimit:
+		t.direction = -1
+	}
+
+	t.lastThroughput = throughput
+	t.lastLatency = latency
+
+	current := t.resizer.PoolSize()
+	step := t.step
+	if step <= 0 {
+		step = current / 10
+	}
+	if step == 0 {
+		step = 1
+	}
+
+	size := current + t.direction*step
+	if size < t.minSize {
+		size = t.minSize
+	}
+	if size > t.maxSize {
+		size = t.maxSize
+	}
+
+	if size == current {
+		return
+	}
+
+	t.logger.Debug("[tuner] resizing pool",
+		"pool", t.name,
+		"from", current,
+		"to", size,
+		"throughput", throughput,
+		"latency", latency,
+	)
+	t.resizer.SetPoolSize(size)
+}
@@ -0,0 +1,63 @@
+package pool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/l2cup/kids1/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeResizer struct {
+	size int
+}
+
+func (f *fakeResizer) PoolSize() int        { return f.size }
+func (f *fakeResizer) SetPoolSize(size int) { f.size = size }
+
+func TestTunerGrowsWhileThroughputRises(t *testing.T) {
+	r := &fakeResizer{size: 10}
+	tuner := NewTuner(&TunerConfig{Logger: testutil.Logger(t), Resizer: r, MinSize: 1, MaxSize: 20, Step: 2, Interval: time.Second})
+
+	tuner.Observe(1000, time.Millisecond)
+	tuner.adjust()
+	assert.Equal(t, 12, r.size)
+
+	tuner.Observe(2000, time.Millisecond)
+	tuner.adjust()
+	assert.Equal(t, 14, r.size)
+}
+
+func TestTunerReversesWhenThroughputDrops(t *testing.T) {
+	r := &fakeResizer{size: 10}
+	tuner := NewTuner(&TunerConfig{Logger: testutil.Logger(t), Resizer: r, MinSize: 1, MaxSize: 20, Step: 2, Interval: time.Second})
+
+	tuner.Observe(1000, time.Millisecond)
+	tuner.adjust()
+	tuner.Observe(500, time.Millisecond)
+	tuner.adjust()
+	assert.Equal(t, 10, r.size)
+}
+
+func TestTunerBacksOffOnLatencyGrowth(t *testing.T) {
+	r := &fakeResizer{size: 10}
+	tuner := NewTuner(&TunerConfig{Logger: testutil.Logger(t), Resizer: r, MinSize: 1, MaxSize: 20, Step: 2, Interval: time.Second})
+
+	tuner.Observe(1000, time.Millisecond)
+	tuner.adjust()
+	tuner.Observe(1000, 10*time.Millisecond)
+	tuner.adjust()
+	assert.Equal(t, 10, r.size)
+}
+
+func TestTunerRespectsBounds(t *testing.T) {
+	r := &fakeResizer{size: 20}
+	tuner := NewTuner(&TunerConfig{Logger: testutil.Logger(t), Resizer: r, MinSize: 1, MaxSize: 20, Step: 2, Interval: time.Second})
+
+	tuner.Observe(1000, time.Millisecond)
+	tuner.adjust()
+	assert.Equal(t, 20, r.size)
+
+	tuner.adjust()
+	assert.Equal(t, 20, r.size)
+}
@@ -0,0 +1,71 @@
+package result
+
+import (
+	"net/url"
+	"strings"
+)
+
+// GetDomainResults sums the keyword counts of the sources per host they were
+// fetched from, the host redirects ended at for redirected sources.
+func (s *Summary) GetDomainResults() map[string]map[string]int64 {
+	s.wg.Wait()
+	return domainResults(s.sources, s.finalURLs)
+}
+
+// GetDomainSourceResults returns the keyword counts of the sources fetched
+// from the domain or one of its subdomains.
+func (s *Summary) GetDomainSourceResults(domain string) map[string]map[string]int64 {
+	s.wg.Wait()
+	return domainSources(s.sources, s.finalURLs, domain)
+}
+
+func domainResults(sources map[string]map[string]int64, finalURLs map[string]string) map[string]map[string]int64 {
+	domains := make(map[string]map[string]int64)
+	for source, results := range sources {
+		domain := sourceDomain(source, finalURLs)
+		if domain == "" {
+			continue
+		}
+
+		domainResults, ok := domains[domain]
+		if !ok {
+			domainResults = make(map[string]int64, len(results))
+			domains[domain] = domainResults
+		}
+		for k, v := range results {
+			domainResults[k] += v
+		}
+	}
+	return domains
+}
+
+func domainSources(
+	sources map[string]map[string]int64,
+	finalURLs map[string]string,
+	domain string,
+) map[string]map[string]int64 {
+	domain = strings.ToLower(domain)
+
+	matching := make(map[string]map[string]int64)
+	for source, results := range sources {
+		host := sourceDomain(source, finalURLs)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			matching[source] = results
+		}
+	}
+	return matching
+}
+
+// sourceDomain is the host a web source was fetched from, empty for sources
+// that aren't urls.
+func sourceDomain(source string, finalURLs map[string]string) string {
+	if finalURL, ok := finalURLs[source]; ok {
+		source = finalURL
+	}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
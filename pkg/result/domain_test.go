@@ -0,0 +1,36 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainResults(t *testing.T) {
+	sources := map[string]map[string]int64{
+		"https://example.com/a":      {"core": 1},
+		"https://Example.com/b":      {"core": 2},
+		"https://docs.example.com/c": {"core": 4},
+		"https://other.org/":         {"core": 8},
+		"corpus_a/file.txt":          {"core": 16},
+		"https://short.link/x":       {"core": 32},
+	}
+	finalURLs := map[string]string{
+		"https://short.link/x": "https://www.example.com/long",
+	}
+
+	assert.Equal(t, map[string]map[string]int64{
+		"example.com":      {"core": 3},
+		"www.example.com":  {"core": 32},
+		"docs.example.com": {"core": 4},
+		"other.org":        {"core": 8},
+	}, domainResults(sources, finalURLs))
+
+	assert.Equal(t, map[string]map[string]int64{
+		"https://example.com/a":      {"core": 1},
+		"https://Example.com/b":      {"core": 2},
+		"https://docs.example.com/c": {"core": 4},
+		"https://short.link/x":       {"core": 32},
+	}, domainSources(sources, finalURLs, "example.com"))
+	assert.Empty(t, domainSources(sources, finalURLs, "short.link"))
+}
@@ -0,0 +1,78 @@
+package result
+
+import "math"
+
+type Metrics struct {
+	Count int64
+	// TermFrequency is the share of the corpus tokens taken by the keyword.
+	TermFrequency float64
+	PerMillion    float64
+	// DocumentFrequency is the share of the corpus documents containing the
+	// keyword.
+	DocumentFrequency float64
+	// TFIDF weighs the term frequency by how rare the keyword is across all
+	// corpora of the same job type.
+	TFIDF float64
+}
+
+type NormalizedSummary struct {
+	KeywordSet   string
+	Documents    int64
+	Tokens       int64
+	SourceTokens map[string]int64
+	Metrics      map[string]Metrics
+}
+
+func (s *Summary) normalize() *NormalizedSummary {
+	s.wg.Wait()
+	defer s.mutex.Unlock()
+	s.mutex.Lock()
+
+	ns := &NormalizedSummary{
+		KeywordSet:   s.keywordSet,
+		Documents:    s.documents,
+		Tokens:       s.tokens,
+		SourceTokens: make(map[string]int64, len(s.sourceTokens)),
+		Metrics:      make(map[string]Metrics, len(s.results)),
+	}
+
+	for source, tokens := range s.sourceTokens {
+		ns.SourceTokens[source] = tokens
+	}
+
+	for k, count := range s.results {
+		m := Metrics{Count: count}
+		if s.tokens > 0 {
+			m.TermFrequency = float64(count) / float64(s.tokens)
+			m.PerMillion = m.TermFrequency * 1e6
+		}
+		if s.documents > 0 {
+			m.DocumentFrequency = float64(s.documentFrequency[k]) / float64(s.documents)
+		}
+		ns.Metrics[k] = m
+	}
+
+	return ns
+}
+
+// weighTFIDF fills in the tf-idf of every keyword, treating each corpus as a
+// single document and using smoothed inverse document frequency.
+func weighTFIDF(summaries map[string]*NormalizedSummary) {
+	corpora := make(map[string]int64)
+	for _, ns := range summaries {
+		for k, m := range ns.Metrics {
+			if m.Count > 0 {
+				corpora[k]++
+			}
+		}
+	}
+
+	n := float64(len(summaries))
+	for _, ns := range summaries {
+		for k, m := range ns.Metrics {
+			idf := math.Log((1+n)/(1+float64(corpora[k]))) + 1
+			m.TFIDF = m.TermFrequency * idf
+			ns.Metrics[k] = m
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package result
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeighTFIDF(t *testing.T) {
+	summaries := map[string]*NormalizedSummary{
+		"a": {Metrics: map[string]Metrics{
+			"common": {Count: 1, TermFrequency: 0.1},
+			"rare":   {Count: 1, TermFrequency: 0.1},
+		}},
+		"b": {Metrics: map[string]Metrics{
+			"common": {Count: 1, TermFrequency: 0.1},
+			"rare":   {Count: 0},
+		}},
+	}
+
+	weighTFIDF(summaries)
+
+	a := summaries["a"].Metrics
+	assert.InDelta(t, 0.1, a["common"].TFIDF, 1e-9)
+	assert.Greater(t, a["rare"].TFIDF, a["common"].TFIDF)
+	assert.Zero(t, summaries["b"].Metrics["rare"].TFIDF)
+}
@@ -10,22 +10,61 @@ import (
 	"github.com/Jeffail/tunny"
 	"github.com/l2cup/kids1/pkg/dispatcher"
 	"github.com/l2cup/kids1/pkg/log"
+	"github.com/l2cup/kids1/pkg/pool"
 	"github.com/l2cup/kids1/pkg/runner"
+	"github.com/l2cup/kids1/pkg/sketch"
+	"github.com/l2cup/kids1/pkg/text"
 	cmap "github.com/orcaman/concurrent-map"
 )
 
 type Retriever interface {
 	runner.Runner
+	pool.Resizer
 
-	InitializeSummary(jobType dispatcher.JobType, corpusName string, jobs int, ttl time.Time)
+	// InitializeSummary creates a summary for the corpus, it returns false
+	// and keeps the existing summary if that one hasn't expired yet.
+	InitializeSummary(jobType dispatcher.JobType, corpusName string, jobs int, ttl time.Time, keywordSet string) bool
 	IncrementResultCount(summaryType dispatcher.JobType, corpusName string) error
 	GetSummary(jobType dispatcher.JobType, corpusName string) (map[string]int64, error)
 	GetSummaries(summaryType dispatcher.JobType) (map[string]map[string]int64, error)
+	GetNormalizedSummaries(summaryType dispatcher.JobType) (map[string]*NormalizedSummary, error)
+	GetKeywordSet(jobType dispatcher.JobType, corpusName string) (string, error)
+	GetLanguages(jobType dispatcher.JobType, corpusName string) (map[string]int64, map[string]string, error)
+	GetLanguageSummary(jobType dispatcher.JobType, corpusName, language string) (map[string]int64, error)
+	GetSourceSummary(jobType dispatcher.JobType, corpusName string) (map[string]map[string]int64, error)
+	// GetDomainSummaries sums the results of every corpus per host the
+	// sources were fetched from.
+	GetDomainSummaries(jobType dispatcher.JobType) (map[string]map[string]int64, error)
+	// GetDomainSources returns the per source results of the domain and its
+	// subdomains, from every corpus if corpusName is empty.
+	GetDomainSources(jobType dispatcher.JobType, corpusName, domain string) (map[string]map[string]int64, error)
+	TopSources(jobType dispatcher.JobType, corpusName, keyword string, n int) ([]SourceCount, error)
+	GetMatches(jobType dispatcher.JobType, corpusName, keyword string) ([]text.Match, error)
+	// GetBlocked returns the urls of the corpus that weren't fetched and why.
+	GetBlocked(jobType dispatcher.JobType, corpusName string) (map[string]string, error)
+	// GetFinalURLs maps the sources of the corpus that were redirected to
+	// where they ended.
+	GetFinalURLs(jobType dispatcher.JobType, corpusName string) (map[string]string, error)
+	// GetFetches returns how every url of the corpus was fetched, by url.
+	GetFetches(jobType dispatcher.JobType, corpusName string) (map[string]*Fetch, error)
+	GetNGrams(jobType dispatcher.JobType, corpusName string, n, k int) ([]sketch.Item, error)
+	GetCooccurrence(jobType dispatcher.JobType, corpusName string) (map[string]map[string]int64, error)
+	GetTopTokens(jobType dispatcher.JobType, corpusName string, k int) ([]sketch.Item, error)
 	QuerySummary(jobType dispatcher.JobType, corpusName string) (map[string]int64, error)
 	DeleteSummary(summaryType dispatcher.JobType)
 	UpdateSummary(results *Results)
 }
 
+type Config struct {
+	Logger            *log.Logger
+	RunnerRegistrator runner.Registrator
+	BufferSize        int
+	PoolSize          int
+	JobTimeoutMS      uint64
+	Tuner             *pool.TunerConfig
+	Vocabulary        *sketch.VocabularyConfig
+}
+
 var _ Retriever = (*retrieverImplementation)(nil)
 var _ runner.Runner = (*retrieverImplementation)(nil)
 
@@ -34,27 +73,50 @@ type retrieverImplementation struct {
 	summariesMap cmap.ConcurrentMap
 	resultsChan  chan *Results
 	pool         *tunny.Pool
+	tuner        *pool.Tuner
+	jobTimeout   time.Duration
+	vocabulary   *sketch.VocabularyConfig
 
 	done chan struct{}
 }
 
-func NewRetrieverImplementation(bufferSize int, logger *log.Logger, registrator runner.Registrator) Retriever {
+func NewRetrieverImplementation(c *Config) Retriever {
+	jobTimeout, err := time.ParseDuration(fmt.Sprintf("%dms", c.JobTimeoutMS))
+	if err != nil {
+		c.Logger.Fatal("couldn't parse retriever job timeout duration", "err", err, "duration", c.JobTimeoutMS)
+	}
+
 	summariesMap := cmap.New()
 	summariesMap.Set(string(dispatcher.FileJobType), cmap.New())
 	summariesMap.Set(string(dispatcher.WebJobType), cmap.New())
 
 	ri := &retrieverImplementation{
-		logger:       logger,
+		logger:       c.Logger,
 		summariesMap: summariesMap,
-		resultsChan:  make(chan *Results, bufferSize),
+		resultsChan:  make(chan *Results, c.BufferSize),
+		jobTimeout:   jobTimeout,
+		vocabulary:   c.Vocabulary,
 		done:         make(chan struct{}),
 	}
 
-	registrator.Register(ri)
-	ri.pool = tunny.NewFunc(bufferSize, ri.poolAddResults)
+	c.RunnerRegistrator.Register(ri)
+	ri.pool = tunny.NewFunc(c.PoolSize, ri.poolAddResults)
+
+	if c.Tuner != nil {
+		c.Tuner.Resizer = ri
+		ri.tuner = pool.NewTuner(c.Tuner)
+	}
 	return ri
 }
 
+func (ri *retrieverImplementation) PoolSize() int {
+	return ri.pool.GetSize()
+}
+
+func (ri *retrieverImplementation) SetPoolSize(size int) {
+	ri.pool.SetSize(size)
+}
+
 func (ri *retrieverImplementation) Start() {
 	for {
 		select {
@@ -76,13 +138,30 @@ func (ri *retrieverImplementation) InitializeSummary(
 	corpusName string,
 	jobs int,
 	ttl time.Time,
-) {
+	keywordSet string,
+) bool {
 	summary := &Summary{
-		wg:      sync.WaitGroup{},
-		counter: int64(jobs),
-		mutex:   sync.Mutex{},
-		results: make(map[string]int64),
-		ttl:     ttl,
+		wg:         sync.WaitGroup{},
+		counter:    int64(jobs),
+		mutex:      sync.Mutex{},
+		results:    make(map[string]int64),
+		keywordSet: keywordSet,
+		sources:    make(map[string]map[string]int64),
+
+		sourceTokens:      make(map[string]int64),
+		documentFrequency: make(map[string]int64),
+		languages:         make(map[string]int64),
+		byLanguage:        make(map[string]map[string]int64),
+		sourceLanguages:   make(map[string]string),
+
+		matches: make(map[string][]text.Match),
+		blocked: make(map[string]string),
+
+		finalURLs: make(map[string]string),
+		fetches:   make(map[string]*Fetch),
+		ttl:       ttl,
+
+		vocabularyConfig: ri.vocabulary,
 	}
 
 	summary.wg.Add(jobs)
@@ -90,7 +169,7 @@ func (ri *retrieverImplementation) InitializeSummary(
 	summaries, ok := ri.summariesMap.Get(string(jobType))
 	if !ok {
 		ri.logger.Error("summaries map for job type doesn't exist")
-		return
+		return false
 	}
 
 	summariesMap, ok := summaries.(cmap.ConcurrentMap)
@@ -106,12 +185,13 @@ func (ri *retrieverImplementation) InitializeSummary(
 		}
 
 		if existing.ttl.After(time.Now()) {
-			return
+			return false
 		}
 	}
 
 	ri.logger.Info("created corpus", "corpus_name", corpusName, "summary", summary)
 	summariesMap.Set(corpusName, summary)
+	return true
 }
 
 func (ri *retrieverImplementation) GetSummary(
@@ -131,6 +211,270 @@ func (ri *retrieverImplementation) GetSummary(
 	return summary.GetResults(), nil
 }
 
+func (ri *retrieverImplementation) GetKeywordSet(
+	summaryType dispatcher.JobType,
+	corpusName string,
+) (string, error) {
+
+	summary, err := ri.getSummary(summaryType, corpusName)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't get summary: ")
+	}
+
+	return summary.keywordSet, nil
+}
+
+func (ri *retrieverImplementation) GetLanguages(
+	summaryType dispatcher.JobType,
+	corpusName string,
+) (map[string]int64, map[string]string, error) {
+
+	summary, err := ri.getSummary(summaryType, corpusName)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "couldn't get summary: ")
+	}
+
+	if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+		return nil, nil, errors.New("summary expired")
+	}
+
+	totals, sources := summary.GetLanguages()
+	return totals, sources, nil
+}
+
+func (ri *retrieverImplementation) GetLanguageSummary(
+	summaryType dispatcher.JobType,
+	corpusName string,
+	language string,
+) (map[string]int64, error) {
+
+	summary, err := ri.getSummary(summaryType, corpusName)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get summary: ")
+	}
+
+	if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+		return nil, errors.New("summary expired")
+	}
+
+	return summary.GetLanguageResults(language), nil
+}
+
+func (ri *retrieverImplementation) GetSourceSummary(
+	summaryType dispatcher.JobType,
+	corpusName string,
+) (map[string]map[string]int64, error) {
+
+	summary, err := ri.getSummary(summaryType, corpusName)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get summary: ")
+	}
+
+	if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+		return nil, errors.New("summary expired")
+	}
+
+	return summary.GetSourceResults(), nil
+}
+
+func (ri *retrieverImplementation) GetDomainSummaries(summaryType dispatcher.JobType) (map[string]map[string]int64, error) {
+	summaries, err := ri.liveSummaries(summaryType)
+	if err != nil {
+		return nil, err
+	}
+
+	domains := make(map[string]map[string]int64)
+	for _, summary := range summaries {
+		for domain, results := range summary.GetDomainResults() {
+			domainResults, ok := domains[domain]
+			if !ok {
+				domainResults = make(map[string]int64, len(results))
+				domains[domain] = domainResults
+			}
+			for k, v := range results {
+				domainResults[k] += v
+			}
+		}
+	}
+	return domains, nil
+}
+
+func (ri *retrieverImplementation) GetDomainSources(
+	summaryType dispatcher.JobType,
+	corpusName string,
+	domain string,
+) (map[string]map[string]int64, error) {
+
+	var summaries []*Summary
+	if corpusName != "" {
+		summary, err := ri.getSummary(summaryType, corpusName)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't get summary: ")
+		}
+
+		if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+			return nil, errors.New("summary expired")
+		}
+		summaries = []*Summary{summary}
+	} else {
+		var err error
+		summaries, err = ri.liveSummaries(summaryType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sources := make(map[string]map[string]int64)
+	for _, summary := range summaries {
+		for source, results := range summary.GetDomainSourceResults(domain) {
+			sources[source] = results
+		}
+	}
+	return sources, nil
+}
+
+func (ri *retrieverImplementation) TopSources(
+	summaryType dispatcher.JobType,
+	corpusName string,
+	keyword string,
+	n int,
+) ([]SourceCount, error) {
+
+	summary, err := ri.getSummary(summaryType, corpusName)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get summary: ")
+	}
+
+	if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+		return nil, errors.New("summary expired")
+	}
+
+	return summary.TopSources(keyword, n), nil
+}
+
+func (ri *retrieverImplementation) GetMatches(
+	summaryType dispatcher.JobType,
+	corpusName string,
+	keyword string,
+) ([]text.Match, error) {
+
+	summary, err := ri.getSummary(summaryType, corpusName)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get summary: ")
+	}
+
+	if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+		return nil, errors.New("summary expired")
+	}
+
+	return summary.GetMatches(keyword), nil
+}
+
+func (ri *retrieverImplementation) GetBlocked(
+	summaryType dispatcher.JobType,
+	corpusName string,
+) (map[string]string, error) {
+
+	summary, err := ri.getSummary(summaryType, corpusName)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get summary: ")
+	}
+
+	if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+		return nil, errors.New("summary expired")
+	}
+
+	return summary.GetBlocked(), nil
+}
+
+func (ri *retrieverImplementation) GetFinalURLs(
+	summaryType dispatcher.JobType,
+	corpusName string,
+) (map[string]string, error) {
+
+	summary, err := ri.getSummary(summaryType, corpusName)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get summary: ")
+	}
+
+	if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+		return nil, errors.New("summary expired")
+	}
+
+	return summary.GetFinalURLs(), nil
+}
+
+func (ri *retrieverImplementation) GetFetches(
+	summaryType dispatcher.JobType,
+	corpusName string,
+) (map[string]*Fetch, error) {
+
+	summary, err := ri.getSummary(summaryType, corpusName)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get summary: ")
+	}
+
+	if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+		return nil, errors.New("summary expired")
+	}
+
+	return summary.GetFetches(), nil
+}
+
+func (ri *retrieverImplementation) GetNGrams(
+	summaryType dispatcher.JobType,
+	corpusName string,
+	n int,
+	k int,
+) ([]sketch.Item, error) {
+
+	summary, err := ri.getSummary(summaryType, corpusName)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get summary: ")
+	}
+
+	if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+		return nil, errors.New("summary expired")
+	}
+
+	return summary.TopNGrams(n, k), nil
+}
+
+func (ri *retrieverImplementation) GetCooccurrence(
+	summaryType dispatcher.JobType,
+	corpusName string,
+) (map[string]map[string]int64, error) {
+
+	summary, err := ri.getSummary(summaryType, corpusName)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get summary: ")
+	}
+
+	if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+		return nil, errors.New("summary expired")
+	}
+
+	return summary.GetCooccurrence(), nil
+}
+
+func (ri *retrieverImplementation) GetTopTokens(
+	summaryType dispatcher.JobType,
+	corpusName string,
+	k int,
+) ([]sketch.Item, error) {
+
+	summary, err := ri.getSummary(summaryType, corpusName)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get summary: ")
+	}
+
+	if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+		return nil, errors.New("summary expired")
+	}
+
+	return summary.TopTokens(k), nil
+}
+
 func (ri *retrieverImplementation) QuerySummary(
 	summaryType dispatcher.JobType,
 	corpusName string,
@@ -184,6 +528,67 @@ func (ri *retrieverImplementation) GetSummaries(summaryType dispatcher.JobType)
 	return retMap, nil
 }
 
+func (ri *retrieverImplementation) GetNormalizedSummaries(
+	summaryType dispatcher.JobType,
+) (map[string]*NormalizedSummary, error) {
+	summaries, ok := ri.summariesMap.Get(string(summaryType))
+	if !ok {
+		ri.logger.Error("summaries map for job type doesn't exist")
+		return nil, errors.New("summaries map for job type doens't exist")
+	}
+
+	summariesMap, ok := summaries.(cmap.ConcurrentMap)
+	if !ok {
+		ri.logger.Fatal("couldn't cast summaries to concurrent map")
+		return nil, errors.New("couldn't cast summaries to concurrent map")
+	}
+
+	retMap := make(map[string]*NormalizedSummary)
+	for kvPair := range summariesMap.IterBuffered() {
+		summary, ok := kvPair.Val.(*Summary)
+		if !ok {
+			return nil, errors.New("map value couldn't be cast as summary")
+		}
+
+		if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+			continue
+		}
+		retMap[kvPair.Key] = summary.normalize()
+	}
+
+	weighTFIDF(retMap)
+	return retMap, nil
+}
+
+// liveSummaries returns the summaries of the job type that haven't expired.
+func (ri *retrieverImplementation) liveSummaries(summaryType dispatcher.JobType) ([]*Summary, error) {
+	summaries, ok := ri.summariesMap.Get(string(summaryType))
+	if !ok {
+		ri.logger.Error("summaries map for job type doesn't exist")
+		return nil, errors.New("summaries map for job type doens't exist")
+	}
+
+	summariesMap, ok := summaries.(cmap.ConcurrentMap)
+	if !ok {
+		ri.logger.Fatal("couldn't cast summaries to concurrent map")
+		return nil, errors.New("couldn't cast summaries to concurrent map")
+	}
+
+	live := make([]*Summary, 0, summariesMap.Count())
+	for kvPair := range summariesMap.IterBuffered() {
+		summary, ok := kvPair.Val.(*Summary)
+		if !ok {
+			return nil, errors.New("map value couldn't be cast as summary")
+		}
+
+		if !summary.ttl.IsZero() && summary.ttl.Before(time.Now()) {
+			continue
+		}
+		live = append(live, summary)
+	}
+	return live, nil
+}
+
 func (ri *retrieverImplementation) UpdateSummary(results *Results) {
 	ri.resultsChan <- results
 	ri.logger.Debug("updated summary", "results", results)
@@ -200,7 +605,9 @@ func (ri *retrieverImplementation) addResults(results *Results) {
 	}
 
 	ri.logger.Debug("starting pool results adding")
-	_, err := ri.pool.ProcessTimed(results, 60*time.Second)
+	start := time.Now()
+	_, err := ri.pool.ProcessTimed(results, ri.jobTimeout)
+	ri.tuner.Observe(0, time.Since(start))
 
 	if err == tunny.ErrJobTimedOut {
 		ri.logger.Error("goroutine timed out", "err", err)
@@ -233,7 +640,7 @@ func (ri *retrieverImplementation) poolAddResults(payload interface{}) interface
 		return errors.Wrap(err, "couldn't get summary")
 	}
 
-	summary.AddResults(results.Results)
+	summary.AddResults(results)
 	ri.logger.Debug("updated results in pool")
 	return nil
 }
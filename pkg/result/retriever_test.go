@@ -0,0 +1,91 @@
+package result
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/testutil"
+)
+
+// addResults sends the results of a new corpus to the retriever, its summary
+// waits for all of them.
+func addResults(t *testing.T, ri Retriever, corpusName string, results ...*Results) {
+	assert.True(t, ri.InitializeSummary(dispatcher.FileJobType, corpusName, len(results), time.Time{}, ""))
+	for _, r := range results {
+		r.JobType = dispatcher.FileJobType
+		r.CorpusName = corpusName
+		ri.UpdateSummary(r)
+	}
+}
+
+func TestSourceSummary(t *testing.T) {
+	ri := NewRetrieverImplementation(&Config{
+		Logger:            testutil.Logger(t),
+		RunnerRegistrator: testutil.Registrator{},
+		BufferSize:        4,
+		PoolSize:          2,
+		JobTimeoutMS:      1000,
+	})
+	go ri.Start()
+	defer ri.Stop()
+
+	addResults(t, ri, "corpus",
+		&Results{Source: "a.txt", Results: map[string]int64{"core": 1, "one": 0}},
+		&Results{Source: "b.txt", Results: map[string]int64{"core": 2, "one": 3}},
+		&Results{Source: "a.txt", Results: map[string]int64{"core": 4, "one": 1}},
+		// results without a source only count towards the corpus totals.
+		&Results{Results: map[string]int64{"core": 8}},
+	)
+
+	sources, err := ri.GetSourceSummary(dispatcher.FileJobType, "corpus")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]int64{
+		"a.txt": {"core": 5, "one": 1},
+		"b.txt": {"core": 2, "one": 3},
+	}, sources)
+
+	totals, err := ri.GetSummary(dispatcher.FileJobType, "corpus")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"core": 15, "one": 4}, totals)
+
+	_, err = ri.GetSourceSummary(dispatcher.FileJobType, "missing")
+	assert.Error(t, err)
+}
+
+func TestTopSources(t *testing.T) {
+	ri := NewRetrieverImplementation(&Config{
+		Logger:            testutil.Logger(t),
+		RunnerRegistrator: testutil.Registrator{},
+		BufferSize:        4,
+		PoolSize:          2,
+		JobTimeoutMS:      1000,
+	})
+	go ri.Start()
+	defer ri.Stop()
+
+	addResults(t, ri, "corpus",
+		&Results{Source: "c.txt", Results: map[string]int64{"core": 2}},
+		&Results{Source: "a.txt", Results: map[string]int64{"core": 2}},
+		&Results{Source: "b.txt", Results: map[string]int64{"core": 5}},
+		&Results{Source: "d.txt", Results: map[string]int64{"core": 0}},
+	)
+
+	top, err := ri.TopSources(dispatcher.FileJobType, "corpus", "core", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []SourceCount{
+		{Source: "b.txt", Count: 5},
+		{Source: "a.txt", Count: 2},
+		{Source: "c.txt", Count: 2},
+	}, top)
+
+	top, err = ri.TopSources(dispatcher.FileJobType, "corpus", "core", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []SourceCount{{Source: "b.txt", Count: 5}, {Source: "a.txt", Count: 2}}, top)
+
+	top, err = ri.TopSources(dispatcher.FileJobType, "corpus", "missing", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, top)
+}
@@ -1,11 +1,15 @@
 package result
 
 import (
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/l2cup/kids1/pkg/dispatcher"
+	"github.com/l2cup/kids1/pkg/sketch"
+	"github.com/l2cup/kids1/pkg/text"
+	"github.com/l2cup/kids1/pkg/text/lang"
 )
 
 type Summaries map[string]*Summary
@@ -16,13 +20,83 @@ type Summary struct {
 
 	mutex   sync.Mutex
 	results map[string]int64
-	ttl     time.Time
+	// keywordSet is the name of the keyword set the corpus is counted with.
+	keywordSet string
+	sources    map[string]map[string]int64
+	// documents and tokens count the sources and tokens added so far, they
+	// back the normalized metrics.
+	documents         int64
+	tokens            int64
+	sourceTokens      map[string]int64
+	documentFrequency map[string]int64
+	// languages counts the sources per detected language and byLanguage
+	// holds the keyword counts of each language.
+	languages       map[string]int64
+	byLanguage      map[string]map[string]int64
+	sourceLanguages map[string]string
+	matches         map[string][]text.Match
+	// blocked are the sources that weren't fetched and why.
+	blocked map[string]string
+	// finalURLs are the urls redirected sources ended at.
+	finalURLs map[string]string
+	// fetches are how the sources were fetched, by source.
+	fetches map[string]*Fetch
+	// analysis is nil until the first results with statistics arrive.
+	analysis *text.Analysis
+	// vocabulary is nil until the first results counted in discover mode.
+	vocabulary       *sketch.Vocabulary
+	vocabularyConfig *sketch.VocabularyConfig
+	ttl              time.Time
 }
 
 type Results struct {
 	JobType    dispatcher.JobType
 	CorpusName string
+	// Source is the file path or url the results were counted from.
+	Source string
+	// FinalURL is the url Source ended at after redirects.
+	FinalURL   string
+	Tokens     int64
+	Language   string
 	Results    map[string]int64
+	Matches    map[string][]text.Match
+	Analysis   *text.Analysis
+	Vocabulary map[string]int64
+	// Blocked is why the source wasn't fetched, e.g. its address is
+	// internal.
+	Blocked string
+	// Fetch is how the source was fetched, nil for sources that weren't.
+	Fetch *Fetch
+}
+
+// Fetch records the download of a web page.
+type Fetch struct {
+	URL string
+	// Status is the http status code, 0 if no response arrived.
+	Status int
+	// Error is why the page wasn't counted, empty if it was.
+	Error string
+	// Skipped is why the page was left out on purpose, e.g. its content type
+	// or size, a skipped page didn't fail.
+	Skipped string
+	Bytes   int64
+	Latency time.Duration
+	// Depth is the number of links followed from the seed url to the page.
+	Depth  int
+	Parent string
+}
+
+// Failed reports whether the page couldn't be fetched or counted.
+func (f *Fetch) Failed() bool {
+	if f.Skipped != "" {
+		return false
+	}
+	return f.Error != "" || f.Status < 200 || f.Status >= 400
+}
+
+type SourceCount struct {
+	Source string
+	Count  int64
 }
 
 func (s *Summary) GetResults() map[string]int64 {
@@ -42,11 +116,109 @@ func (s *Summary) IncrementResultCount() {
 	atomic.AddInt64(&s.counter, 1)
 }
 
-func (s *Summary) AddResults(results map[string]int64) {
+func (s *Summary) GetSourceResults() map[string]map[string]int64 {
+	s.wg.Wait()
+	return s.sources
+}
+
+func (s *Summary) GetLanguages() (map[string]int64, map[string]string) {
+	s.wg.Wait()
+	return s.languages, s.sourceLanguages
+}
+
+// GetLanguageResults sums the keyword counts of every language matching
+// filter, see lang.Matches.
+func (s *Summary) GetLanguageResults(filter string) map[string]int64 {
+	s.wg.Wait()
+
+	results := make(map[string]int64, len(s.results))
+	for k := range s.results {
+		results[k] = 0
+	}
+
+	for language, languageResults := range s.byLanguage {
+		if !lang.Matches(language, filter) {
+			continue
+		}
+		for k, v := range languageResults {
+			results[k] += v
+		}
+	}
+	return results
+}
+
+func (s *Summary) GetBlocked() map[string]string {
+	s.wg.Wait()
+	return s.blocked
+}
+
+func (s *Summary) GetFinalURLs() map[string]string {
+	s.wg.Wait()
+	return s.finalURLs
+}
+
+func (s *Summary) GetFetches() map[string]*Fetch {
+	s.wg.Wait()
+	return s.fetches
+}
+
+func (s *Summary) GetMatches(keyword string) []text.Match {
+	s.wg.Wait()
+	return s.matches[keyword]
+}
+
+func (s *Summary) TopNGrams(n, k int) []sketch.Item {
+	s.wg.Wait()
+	if s.analysis == nil || s.analysis.NGrams[n] == nil {
+		return nil
+	}
+	return s.analysis.NGrams[n].Top(k)
+}
+
+func (s *Summary) TopTokens(k int) []sketch.Item {
+	s.wg.Wait()
+	if s.vocabulary == nil {
+		return nil
+	}
+	return s.vocabulary.Top(k)
+}
+
+func (s *Summary) GetCooccurrence() map[string]map[string]int64 {
+	s.wg.Wait()
+	if s.analysis == nil {
+		return nil
+	}
+	return s.analysis.Cooccurrence
+}
+
+func (s *Summary) TopSources(keyword string, n int) []SourceCount {
+	s.wg.Wait()
+
+	top := make([]SourceCount, 0, len(s.sources))
+	for source, results := range s.sources {
+		if count := results[keyword]; count > 0 {
+			top = append(top, SourceCount{Source: source, Count: count})
+		}
+	}
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count == top[j].Count {
+			return top[i].Source < top[j].Source
+		}
+		return top[i].Count > top[j].Count
+	})
+
+	if n > 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+func (s *Summary) AddResults(results *Results) {
 	defer s.mutex.Unlock()
 	s.mutex.Lock()
 
-	for k, v := range results {
+	for k, v := range results.Results {
 		if existing, ok := s.results[k]; ok {
 			s.results[k] = existing + v
 			continue
@@ -54,6 +226,75 @@ func (s *Summary) AddResults(results map[string]int64) {
 		s.results[k] = v
 	}
 
+	if results.Source != "" && results.Results != nil {
+		s.documents++
+		s.tokens += results.Tokens
+		s.sourceTokens[results.Source] += results.Tokens
+		for k, v := range results.Results {
+			if v > 0 {
+				s.documentFrequency[k]++
+			}
+		}
+
+		if results.Language != "" {
+			s.languages[results.Language]++
+			s.sourceLanguages[results.Source] = results.Language
+
+			languageResults, ok := s.byLanguage[results.Language]
+			if !ok {
+				languageResults = make(map[string]int64, len(results.Results))
+				s.byLanguage[results.Language] = languageResults
+			}
+			for k, v := range results.Results {
+				languageResults[k] += v
+			}
+		}
+
+		sourceResults, ok := s.sources[results.Source]
+		if !ok {
+			sourceResults = make(map[string]int64, len(results.Results))
+			s.sources[results.Source] = sourceResults
+		}
+		for k, v := range results.Results {
+			sourceResults[k] += v
+		}
+	}
+
+	if results.FinalURL != "" && results.FinalURL != results.Source {
+		s.finalURLs[results.Source] = results.FinalURL
+	}
+
+	if results.Fetch != nil {
+		s.fetches[results.Fetch.URL] = results.Fetch
+	}
+
+	if results.Source != "" && results.Blocked != "" {
+		s.blocked[results.Source] = results.Blocked
+	}
+
+	for k, matches := range results.Matches {
+		for _, m := range matches {
+			m.Source = results.Source
+			s.matches[k] = append(s.matches[k], m)
+		}
+	}
+
+	if results.Analysis != nil {
+		if s.analysis == nil {
+			s.analysis = text.NewAnalysis()
+		}
+		text.MergeAnalysis(s.analysis, results.Analysis)
+	}
+
+	if results.Vocabulary != nil {
+		if s.vocabulary == nil {
+			s.vocabulary = sketch.NewVocabulary(s.vocabularyConfig)
+		}
+		for word, count := range results.Vocabulary {
+			s.vocabulary.Add(word, count)
+		}
+	}
+
 	s.wg.Done()
 	atomic.AddInt64(&s.counter, -1)
 }
@@ -0,0 +1,17 @@
+package result
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchFailed(t *testing.T) {
+	assert.False(t, (&Fetch{Status: http.StatusOK}).Failed())
+	assert.False(t, (&Fetch{Status: http.StatusNotModified}).Failed())
+	assert.True(t, (&Fetch{Status: http.StatusInternalServerError}).Failed())
+	assert.True(t, (&Fetch{Status: http.StatusOK, Error: "skipped"}).Failed())
+	assert.True(t, (&Fetch{Error: "timeout"}).Failed())
+	assert.False(t, (&Fetch{Status: http.StatusOK, Skipped: "content too large"}).Failed())
+}
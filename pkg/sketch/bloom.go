@@ -0,0 +1,64 @@
+package sketch
+
+import (
+	"math"
+)
+
+// Bloom is a set in fixed memory, it may report a key it hasn't seen as
+// present but never misses a key it has.
+type Bloom struct {
+	bits   []uint64
+	size   uint32
+	hashes uint32
+}
+
+// NewBloom sizes a filter for capacity keys at the given false positive
+// rate.
+func NewBloom(capacity int, falsePositiveRate float64) *Bloom {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	size := math.Ceil(-float64(capacity) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	if size > math.MaxUint32 {
+		size = math.MaxUint32
+	}
+	hashes := math.Max(1, math.Round(size/float64(capacity)*math.Ln2))
+
+	return &Bloom{
+		bits:   make([]uint64, (uint64(size)+63)/64),
+		size:   uint32(size),
+		hashes: uint32(hashes),
+	}
+}
+
+// Add adds the key and reports whether it was already present.
+func (b *Bloom) Add(key string) bool {
+	h1, h2 := hashes(key)
+
+	present := true
+	for i := uint32(0); i < b.hashes; i++ {
+		bit := (h1 + i*h2) % b.size
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if b.bits[word]&mask == 0 {
+			present = false
+			b.bits[word] |= mask
+		}
+	}
+	return present
+}
+
+func (b *Bloom) Contains(key string) bool {
+	h1, h2 := hashes(key)
+
+	for i := uint32(0); i < b.hashes; i++ {
+		bit := (h1 + i*h2) % b.size
+		if b.bits[bit/64]&(uint64(1)<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+package sketch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloom(t *testing.T) {
+	b := NewBloom(10000, 0.01)
+
+	assert.False(t, b.Add("https://example.com/"))
+	assert.True(t, b.Add("https://example.com/"))
+
+	for i := 0; i < 10000; i++ {
+		b.Add(fmt.Sprintf("https://example.com/%d", i))
+	}
+	for i := 0; i < 10000; i++ {
+		assert.True(t, b.Contains(fmt.Sprintf("https://example.com/%d", i)))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if b.Contains(fmt.Sprintf("https://example.org/%d", i)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 300)
+}
@@ -0,0 +1,61 @@
+package sketch
+
+import (
+	"hash/fnv"
+)
+
+// CountMin estimates key frequencies in fixed memory, it never underestimates
+// a count and overestimates by at most the sum of colliding counts.
+type CountMin struct {
+	width uint32
+	depth uint32
+	table [][]int64
+}
+
+func NewCountMin(width, depth int) *CountMin {
+	table := make([][]int64, depth)
+	for i := range table {
+		table[i] = make([]int64, width)
+	}
+
+	return &CountMin{
+		width: uint32(width),
+		depth: uint32(depth),
+		table: table,
+	}
+}
+
+func (c *CountMin) Add(key string, count int64) int64 {
+	h1, h2 := hashes(key)
+
+	estimate := int64(-1)
+	for i := uint32(0); i < c.depth; i++ {
+		cell := &c.table[i][(h1+i*h2)%c.width]
+		*cell += count
+		if estimate < 0 || *cell < estimate {
+			estimate = *cell
+		}
+	}
+	return estimate
+}
+
+func (c *CountMin) Estimate(key string) int64 {
+	h1, h2 := hashes(key)
+
+	estimate := int64(-1)
+	for i := uint32(0); i < c.depth; i++ {
+		cell := c.table[i][(h1+i*h2)%c.width]
+		if estimate < 0 || cell < estimate {
+			estimate = cell
+		}
+	}
+	return estimate
+}
+
+// hashes derives the row hashes with double hashing over a single fnv sum.
+func hashes(key string) (uint32, uint32) {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	sum := h.Sum64()
+	return uint32(sum), uint32(sum>>32) | 1
+}
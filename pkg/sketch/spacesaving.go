@@ -0,0 +1,139 @@
+package sketch
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/gob"
+)
+
+type Item struct {
+	Key   string
+	Count int64
+	// Error is the upper bound of the overestimation of Count.
+	Error int64
+}
+
+// SpaceSaving is a heavy hitters sketch which tracks at most capacity keys,
+// evicting the least frequent key when a new one arrives.
+type SpaceSaving struct {
+	capacity int
+	items    map[string]*entry
+	heap     entryHeap
+}
+
+type entry struct {
+	Item
+	index int
+}
+
+func NewSpaceSaving(capacity int) *SpaceSaving {
+	return &SpaceSaving{
+		capacity: capacity,
+		items:    make(map[string]*entry, capacity),
+		heap:     make(entryHeap, 0, capacity),
+	}
+}
+
+func (s *SpaceSaving) Capacity() int {
+	return s.capacity
+}
+
+func (s *SpaceSaving) Offer(key string, count int64) {
+	if e, ok := s.items[key]; ok {
+		e.Count += count
+		heap.Fix(&s.heap, e.index)
+		return
+	}
+
+	if len(s.heap) < s.capacity {
+		e := &entry{Item: Item{Key: key, Count: count}}
+		s.items[key] = e
+		heap.Push(&s.heap, e)
+		return
+	}
+
+	if s.capacity == 0 {
+		return
+	}
+
+	min := s.heap[0]
+	delete(s.items, min.Key)
+	min.Error = min.Count
+	min.Count += count
+	min.Key = key
+	s.items[key] = min
+	heap.Fix(&s.heap, min.index)
+}
+
+func (s *SpaceSaving) Merge(other *SpaceSaving) {
+	for _, e := range other.heap {
+		s.Offer(e.Key, e.Count)
+	}
+}
+
+// Top returns the k most frequent keys, all of them if k isn't positive.
+func (s *SpaceSaving) Top(k int) []Item {
+	items := make([]Item, 0, len(s.heap))
+	for _, e := range s.heap {
+		items = append(items, e.Item)
+	}
+
+	return sortItems(items, k)
+}
+
+// spaceSavingState is the gob representation of a SpaceSaving sketch.
+type spaceSavingState struct {
+	Capacity int
+	Items    []Item
+}
+
+func (s *SpaceSaving) GobEncode() ([]byte, error) {
+	state := spaceSavingState{Capacity: s.capacity, Items: make([]Item, 0, len(s.heap))}
+	for _, e := range s.heap {
+		state.Items = append(state.Items, e.Item)
+	}
+
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(&state)
+	return b.Bytes(), err
+}
+
+func (s *SpaceSaving) GobDecode(data []byte) error {
+	state := spaceSavingState{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+
+	*s = *NewSpaceSaving(state.Capacity)
+	for _, item := range state.Items {
+		e := &entry{Item: item, index: len(s.heap)}
+		s.items[item.Key] = e
+		s.heap = append(s.heap, e)
+	}
+	heap.Init(&s.heap)
+	return nil
+}
+
+type entryHeap []*entry
+
+func (h entryHeap) Len() int           { return len(h) }
+func (h entryHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+
+func (h entryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *entryHeap) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *entryHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
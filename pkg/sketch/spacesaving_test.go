@@ -0,0 +1,59 @@
+package sketch
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpaceSavingKeepsHeavyHitters(t *testing.T) {
+	s := NewSpaceSaving(3)
+
+	for i := 0; i < 10; i++ {
+		s.Offer("a", 1)
+	}
+	for i := 0; i < 5; i++ {
+		s.Offer("b", 1)
+	}
+	for _, key := range []string{"c", "d", "e", "f"} {
+		s.Offer(key, 1)
+	}
+
+	top := s.Top(2)
+	assert.Len(t, top, 2)
+	assert.Equal(t, "a", top[0].Key)
+	assert.Equal(t, int64(10), top[0].Count)
+	assert.Equal(t, "b", top[1].Key)
+	assert.Equal(t, int64(5), top[1].Count)
+}
+
+func TestSpaceSavingMerge(t *testing.T) {
+	a, b := NewSpaceSaving(2), NewSpaceSaving(2)
+	a.Offer("x", 3)
+	b.Offer("x", 2)
+	b.Offer("y", 1)
+
+	a.Merge(b)
+
+	assert.Equal(t, []Item{{Key: "x", Count: 5}, {Key: "y", Count: 1}}, a.Top(0))
+}
+
+func TestSpaceSavingGob(t *testing.T) {
+	s := NewSpaceSaving(2)
+	for _, key := range []string{"a", "a", "b", "c", "a"} {
+		s.Offer(key, 1)
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, gob.NewEncoder(&b).Encode(s))
+
+	decoded := &SpaceSaving{}
+	assert.NoError(t, gob.NewDecoder(&b).Decode(decoded))
+	assert.Equal(t, s.Top(0), decoded.Top(0))
+
+	decoded.Offer("d", 5)
+	assert.Equal(t, "d", decoded.Top(1)[0].Key)
+	assert.Equal(t, 2, decoded.Capacity())
+}
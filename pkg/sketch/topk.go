@@ -0,0 +1,70 @@
+package sketch
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// TopK keeps the k keys with the highest estimated counts.
+type TopK struct {
+	k     int
+	items map[string]*entry
+	heap  entryHeap
+}
+
+func NewTopK(k int) *TopK {
+	return &TopK{
+		k:     k,
+		items: make(map[string]*entry, k),
+		heap:  make(entryHeap, 0, k),
+	}
+}
+
+// Update records the latest estimate for key.
+func (t *TopK) Update(key string, estimate int64) {
+	if e, ok := t.items[key]; ok {
+		e.Count = estimate
+		heap.Fix(&t.heap, e.index)
+		return
+	}
+
+	if len(t.heap) < t.k {
+		e := &entry{Item: Item{Key: key, Count: estimate}}
+		t.items[key] = e
+		heap.Push(&t.heap, e)
+		return
+	}
+
+	if t.k == 0 || t.heap[0].Count >= estimate {
+		return
+	}
+
+	min := t.heap[0]
+	delete(t.items, min.Key)
+	min.Key = key
+	min.Count = estimate
+	t.items[key] = min
+	heap.Fix(&t.heap, 0)
+}
+
+func (t *TopK) Top(k int) []Item {
+	items := make([]Item, 0, len(t.heap))
+	for _, e := range t.heap {
+		items = append(items, e.Item)
+	}
+	return sortItems(items, k)
+}
+
+func sortItems(items []Item, k int) []Item {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count == items[j].Count {
+			return items[i].Key < items[j].Key
+		}
+		return items[i].Count > items[j].Count
+	})
+
+	if k > 0 && len(items) > k {
+		items = items[:k]
+	}
+	return items
+}
@@ -0,0 +1,64 @@
+package sketch
+
+type VocabularyConfig struct {
+	// ExactLimit is the number of distinct keys counted exactly before the
+	// vocabulary switches to a Count-Min sketch.
+	ExactLimit int
+	TopK       int
+	Width      int
+	Depth      int
+}
+
+// Vocabulary counts every key exactly while it is small and falls back to a
+// Count-Min sketch with a top-k heap once it grows past the exact limit.
+type Vocabulary struct {
+	config *VocabularyConfig
+	exact  map[string]int64
+	sketch *CountMin
+	top    *TopK
+}
+
+func NewVocabulary(c *VocabularyConfig) *Vocabulary {
+	return &Vocabulary{
+		config: c,
+		exact:  make(map[string]int64),
+	}
+}
+
+func (v *Vocabulary) Exact() bool {
+	return v.exact != nil
+}
+
+func (v *Vocabulary) Add(key string, count int64) {
+	if v.exact != nil {
+		v.exact[key] += count
+		if len(v.exact) > v.config.ExactLimit {
+			v.switchToSketch()
+		}
+		return
+	}
+
+	v.top.Update(key, v.sketch.Add(key, count))
+}
+
+func (v *Vocabulary) Top(k int) []Item {
+	if v.exact == nil {
+		return v.top.Top(k)
+	}
+
+	items := make([]Item, 0, len(v.exact))
+	for key, count := range v.exact {
+		items = append(items, Item{Key: key, Count: count})
+	}
+	return sortItems(items, k)
+}
+
+func (v *Vocabulary) switchToSketch() {
+	v.sketch = NewCountMin(v.config.Width, v.config.Depth)
+	v.top = NewTopK(v.config.TopK)
+
+	for key, count := range v.exact {
+		v.top.Update(key, v.sketch.Add(key, count))
+	}
+	v.exact = nil
+}
@@ -0,0 +1,34 @@
+package sketch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountMinNeverUnderestimates(t *testing.T) {
+	c := NewCountMin(16, 3)
+	c.Add("a", 5)
+	c.Add("b", 2)
+
+	assert.GreaterOrEqual(t, c.Estimate("a"), int64(5))
+	assert.GreaterOrEqual(t, c.Estimate("b"), int64(2))
+}
+
+func TestVocabularySwitchesToSketch(t *testing.T) {
+	v := NewVocabulary(&VocabularyConfig{ExactLimit: 2, TopK: 2, Width: 1024, Depth: 4})
+
+	v.Add("a", 10)
+	v.Add("b", 5)
+	assert.True(t, v.Exact())
+
+	v.Add("c", 1)
+	v.Add("a", 1)
+	assert.False(t, v.Exact())
+
+	top := v.Top(2)
+	assert.Len(t, top, 2)
+	assert.Equal(t, "a", top[0].Key)
+	assert.GreaterOrEqual(t, top[0].Count, int64(11))
+	assert.Equal(t, "b", top[1].Key)
+}
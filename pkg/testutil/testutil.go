@@ -0,0 +1,28 @@
+// Package testutil holds the fixtures shared by the tests of the other
+// packages, it's only imported from _test.go files.
+package testutil
+
+import (
+	"testing"
+
+	"github.com/l2cup/kids1/pkg/log"
+	"github.com/l2cup/kids1/pkg/runner"
+)
+
+// Logger returns a logger that only prints errors, so passing tests stay
+// quiet.
+func Logger(t *testing.T) *log.Logger {
+	t.Helper()
+
+	logger, err := log.NewLogger(&log.Config{LogVerbosity: log.ErrorVerbosity})
+	if err != nil {
+		t.Fatal("couldn't create test logger: ", err)
+	}
+	return logger
+}
+
+// Registrator drops the runners registered with it, tests start the ones
+// they need themselves.
+type Registrator struct{}
+
+func (Registrator) Register(r runner.Runner) {}
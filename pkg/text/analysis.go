@@ -0,0 +1,113 @@
+package text
+
+import (
+	"strings"
+
+	"github.com/l2cup/kids1/pkg/sketch"
+)
+
+type AnalyzerConfig struct {
+	NGramSizes []int
+	// SketchSize bounds the number of distinct n-grams tracked per size.
+	SketchSize int
+	// Window is the distance in tokens within which two keywords co-occur.
+	Window int
+}
+
+type Analysis struct {
+	NGrams       map[int]*sketch.SpaceSaving
+	Cooccurrence map[string]map[string]int64
+}
+
+func (c *Counter) analyze(tokens []Token, words []string) *Analysis {
+	analysis := &Analysis{
+		NGrams:       make(map[int]*sketch.SpaceSaving, len(c.analysis.NGramSizes)),
+		Cooccurrence: make(map[string]map[string]int64),
+	}
+
+	for _, n := range c.analysis.NGramSizes {
+		ngrams := sketch.NewSpaceSaving(c.analysis.SketchSize)
+		for i := 0; i+n <= len(words); i++ {
+			if c.hasStopWord(words[i : i+n]) {
+				continue
+			}
+			ngrams.Offer(strings.Join(words[i:i+n], " "), 1)
+		}
+		analysis.NGrams[n] = ngrams
+	}
+
+	type occurrence struct {
+		position int
+		keywords []string
+	}
+
+	occurrences := make([]occurrence, 0)
+	for i, token := range tokens {
+		if keywords, ok := c.lookup[c.form(token.Text)]; ok {
+			occurrences = append(occurrences, occurrence{position: i, keywords: keywords})
+		}
+	}
+
+	for i, p := range occurrences {
+		for _, q := range occurrences[i+1:] {
+			if q.position-p.position > c.analysis.Window {
+				break
+			}
+
+			for _, a := range p.keywords {
+				for _, b := range q.keywords {
+					if a == b {
+						continue
+					}
+					addCooccurrence(analysis.Cooccurrence, a, b, 1)
+					addCooccurrence(analysis.Cooccurrence, b, a, 1)
+				}
+			}
+		}
+	}
+
+	return analysis
+}
+
+func (c *Counter) hasStopWord(words []string) bool {
+	for _, word := range words {
+		if _, ok := c.stopWords[word]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func addCooccurrence(matrix map[string]map[string]int64, a, b string, count int64) {
+	row, ok := matrix[a]
+	if !ok {
+		row = make(map[string]int64)
+		matrix[a] = row
+	}
+	row[b] += count
+}
+
+// MergeAnalysis adds the statistics of src into dst.
+func MergeAnalysis(dst, src *Analysis) {
+	for n, ngrams := range src.NGrams {
+		existing, ok := dst.NGrams[n]
+		if !ok {
+			existing = sketch.NewSpaceSaving(ngrams.Capacity())
+			dst.NGrams[n] = existing
+		}
+		existing.Merge(ngrams)
+	}
+
+	for a, row := range src.Cooccurrence {
+		for b, count := range row {
+			addCooccurrence(dst.Cooccurrence, a, b, count)
+		}
+	}
+}
+
+func NewAnalysis() *Analysis {
+	return &Analysis{
+		NGrams:       make(map[int]*sketch.SpaceSaving),
+		Cooccurrence: make(map[string]map[string]int64),
+	}
+}
@@ -0,0 +1,236 @@
+package text
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/l2cup/kids1/pkg/text/lang"
+	"github.com/l2cup/kids1/pkg/text/stem"
+	"github.com/l2cup/kids1/pkg/text/translit"
+)
+
+type CounterConfig struct {
+	Keywords []string
+	// Context enables recording the position and surroundings of every match.
+	Context bool
+	// ContextWindow is the number of bytes kept on each side of a match.
+	ContextWindow int
+	// MaxMatches caps the matches recorded per keyword for a single source.
+	MaxMatches int
+	// Analysis enables n-gram and co-occurrence statistics when set.
+	Analysis *AnalyzerConfig
+	// Discover counts every normalized word, not only the keywords.
+	Discover bool
+	// Words keeps the normalized words of the counted data.
+	Words bool
+	// Stemmer reduces both keywords and tokens to their stems before they're
+	// matched, nil matches tokens exactly.
+	Stemmer stem.Stemmer
+	// StopWords are left out of the discovered vocabulary and n-grams.
+	StopWords map[string]struct{}
+	// DetectLanguage guesses the language of the counted data.
+	DetectLanguage bool
+	// Transliterate rewrites Serbian Cyrillic to Latin in both keywords and
+	// tokens, so Beograd matches Београд.
+	Transliterate bool
+}
+
+type Match struct {
+	Source  string
+	Offset  int
+	Line    int
+	Snippet string
+	// KeywordStart and KeywordEnd locate the keyword within the snippet.
+	KeywordStart int
+	KeywordEnd   int
+}
+
+type Count struct {
+	// Tokens is the number of tokens in the counted data.
+	Tokens     int64
+	Language   string
+	Results    map[string]int64
+	Matches    map[string][]Match
+	Analysis   *Analysis
+	Vocabulary map[string]int64
+	Words      []string
+}
+
+// Counter counts keyword occurrences, it's shared by the file and web
+// crawlers so both count words the same way.
+type Counter struct {
+	keywords      []string
+	context       bool
+	contextWindow int
+	maxMatches    int
+	analysis      *AnalyzerConfig
+	discover      bool
+	words         bool
+	stemmer       stem.Stemmer
+	stopWords     map[string]struct{}
+	detectLang    bool
+	transliterate bool
+	signature     string
+	// lookup maps the matched form of a token to the keywords it counts for.
+	lookup map[string][]string
+}
+
+func NewCounter(c *CounterConfig) *Counter {
+	counter := &Counter{
+		keywords:      c.Keywords,
+		context:       c.Context,
+		contextWindow: c.ContextWindow,
+		maxMatches:    c.MaxMatches,
+		analysis:      c.Analysis,
+		discover:      c.Discover,
+		words:         c.Words,
+		stemmer:       c.Stemmer,
+		stopWords:     c.StopWords,
+		detectLang:    c.DetectLanguage,
+		transliterate: c.Transliterate,
+		lookup:        make(map[string][]string, len(c.Keywords)),
+	}
+
+	for _, word := range c.Keywords {
+		form := counter.form(word)
+		counter.lookup[form] = append(counter.lookup[form], word)
+	}
+
+	analysis := AnalyzerConfig{}
+	if c.Analysis != nil {
+		analysis = *c.Analysis
+	}
+	counter.signature = fmt.Sprintf("%q %T %t %t %d %d %+v %t %t %t %x",
+		c.Keywords, c.Stemmer, c.Transliterate, c.Context, c.ContextWindow, c.MaxMatches,
+		analysis, c.Discover, c.Words, c.DetectLanguage, stopWordsHash(c.StopWords))
+
+	return counter
+}
+
+// stopWordsHash identifies a stop word list, whatever order it's kept in.
+func stopWordsHash(stopWords map[string]struct{}) uint64 {
+	words := make([]string, 0, len(stopWords))
+	for word := range stopWords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+
+	h := fnv.New64a()
+	for _, word := range words {
+		h.Write([]byte(word))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// Signature identifies the settings of the counter, a count made by one
+// counter can be reused by any counter with the same signature.
+func (c *Counter) Signature() string {
+	return c.signature
+}
+
+// form is what a token or keyword is reduced to before matching.
+func (c *Counter) form(token string) string {
+	if c.transliterate {
+		token = translit.Latin(token)
+	}
+	if c.stemmer == nil {
+		return token
+	}
+	return c.stemmer.Stem(Normalize(token))
+}
+
+func (c *Counter) Count(data []byte) *Count {
+	count := &Count{
+		Results: make(map[string]int64, len(c.keywords)),
+	}
+	for _, word := range c.keywords {
+		count.Results[word] = 0
+	}
+
+	if c.context {
+		count.Matches = make(map[string][]Match)
+	}
+
+	if c.detectLang {
+		count.Language = lang.Detect(data)
+	}
+
+	tokens := Tokenize(data)
+	count.Tokens = int64(len(tokens))
+	for _, token := range tokens {
+		for _, keyword := range c.lookup[c.form(token.Text)] {
+			count.Results[keyword]++
+
+			if c.context && len(count.Matches[keyword]) < c.maxMatches {
+				count.Matches[keyword] = append(count.Matches[keyword], c.match(data, token))
+			}
+		}
+	}
+
+	if c.analysis == nil && !c.discover && !c.words {
+		return count
+	}
+
+	words := normalizeTokens(tokens)
+	if c.transliterate {
+		for i, word := range words {
+			words[i] = translit.Latin(word)
+		}
+	}
+
+	if c.analysis != nil {
+		count.Analysis = c.analyze(tokens, words)
+	}
+
+	if c.discover {
+		count.Vocabulary = make(map[string]int64)
+		for _, word := range words {
+			if _, ok := c.stopWords[word]; !ok {
+				count.Vocabulary[word]++
+			}
+		}
+	}
+
+	if c.words {
+		count.Words = words
+	}
+
+	return count
+}
+
+func (c *Counter) match(data []byte, token Token) Match {
+	start := token.Offset - c.contextWindow
+	if start < 0 {
+		start = 0
+	}
+	for start > 0 && !utf8.RuneStart(data[start]) {
+		start--
+	}
+
+	end := token.Offset + len(token.Text) + c.contextWindow
+	if end > len(data) {
+		end = len(data)
+	}
+	for end < len(data) && !utf8.RuneStart(data[end]) {
+		end++
+	}
+
+	snippet := make([]byte, end-start)
+	for i, b := range data[start:end] {
+		if b == '\n' || b == '\r' || b == '\t' {
+			b = ' '
+		}
+		snippet[i] = b
+	}
+
+	return Match{
+		Offset:       token.Offset,
+		Line:         token.Line,
+		Snippet:      string(snippet),
+		KeywordStart: token.Offset - start,
+		KeywordEnd:   token.Offset - start + len(token.Text),
+	}
+}
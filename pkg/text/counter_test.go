@@ -0,0 +1,116 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/l2cup/kids1/pkg/sketch"
+	"github.com/l2cup/kids1/pkg/text/stem"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizePositions(t *testing.T) {
+	tokens := Tokenize([]byte("one two\n  three"))
+
+	assert.Equal(t, []Token{
+		{Text: "one", Offset: 0, Line: 1},
+		{Text: "two", Offset: 4, Line: 1},
+		{Text: "three", Offset: 10, Line: 2},
+	}, tokens)
+}
+
+func TestCounterCountsKeywords(t *testing.T) {
+	counter := NewCounter(&CounterConfig{Keywords: []string{"one", "two"}})
+
+	count := counter.Count([]byte("one two one three"))
+
+	assert.Equal(t, map[string]int64{"one": 2, "two": 1}, count.Results)
+	assert.Nil(t, count.Matches)
+}
+
+func TestCounterRecordsContext(t *testing.T) {
+	counter := NewCounter(&CounterConfig{
+		Keywords:      []string{"two"},
+		Context:       true,
+		ContextWindow: 4,
+		MaxMatches:    1,
+	})
+
+	count := counter.Count([]byte("one\ntwo three two"))
+
+	assert.Equal(t, int64(2), count.Results["two"])
+	assert.Len(t, count.Matches["two"], 1)
+
+	m := count.Matches["two"][0]
+	assert.Equal(t, 4, m.Offset)
+	assert.Equal(t, 2, m.Line)
+	assert.Equal(t, "one two thr", m.Snippet)
+	assert.Equal(t, "two", m.Snippet[m.KeywordStart:m.KeywordEnd])
+}
+
+func TestCounterAnalysis(t *testing.T) {
+	counter := NewCounter(&CounterConfig{
+		Keywords: []string{"one", "two", "three"},
+		Analysis: &AnalyzerConfig{NGramSizes: []int{2}, SketchSize: 10, Window: 2},
+	})
+
+	count := counter.Count([]byte("one two. One two x three"))
+
+	top := count.Analysis.NGrams[2].Top(1)
+	assert.Equal(t, "one two", top[0].Key)
+	assert.Equal(t, int64(2), top[0].Count)
+
+	assert.Equal(t, int64(1), count.Analysis.Cooccurrence["two"]["three"])
+	assert.Equal(t, int64(1), count.Analysis.Cooccurrence["three"]["two"])
+	assert.Zero(t, count.Analysis.Cooccurrence["one"]["two"])
+}
+
+func TestCounterStemming(t *testing.T) {
+	counter := NewCounter(&CounterConfig{
+		Keywords: []string{"run", "Runners"},
+		Stemmer:  stem.English{},
+	})
+
+	count := counter.Count([]byte("running runs. Run runner ran"))
+
+	assert.Equal(t, map[string]int64{"run": 3, "Runners": 1}, count.Results)
+}
+
+func TestCounterStopWords(t *testing.T) {
+	counter := NewCounter(&CounterConfig{
+		Discover:  true,
+		Analysis:  &AnalyzerConfig{NGramSizes: []int{2}, SketchSize: 10, Window: 1},
+		StopWords: map[string]struct{}{"the": {}},
+	})
+
+	count := counter.Count([]byte("the quick fox the fox"))
+
+	assert.Equal(t, map[string]int64{"quick": 1, "fox": 2}, count.Vocabulary)
+	assert.Equal(t, []sketch.Item{{Key: "quick fox", Count: 1}}, count.Analysis.NGrams[2].Top(0))
+}
+
+func TestCounterTransliteration(t *testing.T) {
+	counter := NewCounter(&CounterConfig{
+		Keywords:      []string{"Beograd", "Љубљана"},
+		Discover:      true,
+		Transliterate: true,
+	})
+
+	count := counter.Count([]byte("Београд Beograd Ljubljana Љубљана"))
+
+	assert.Equal(t, map[string]int64{"Beograd": 2, "Љубљана": 2}, count.Results)
+	assert.Equal(t, map[string]int64{"beograd": 2, "ljubljana": 2}, count.Vocabulary)
+}
+
+func TestCounterSignatureStopWords(t *testing.T) {
+	signature := func(stopWords ...string) string {
+		words := make(map[string]struct{}, len(stopWords))
+		for _, word := range stopWords {
+			words[word] = struct{}{}
+		}
+		return NewCounter(&CounterConfig{Keywords: []string{"one"}, StopWords: words}).Signature()
+	}
+
+	assert.Equal(t, signature("a", "the"), signature("the", "a"))
+	assert.NotEqual(t, signature("a", "the"), signature("a", "an"))
+	assert.NotEqual(t, signature(), signature("a"))
+}
@@ -0,0 +1,133 @@
+package lang
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	English         = "en"
+	SerbianLatin    = "sr-Latn"
+	SerbianCyrillic = "sr-Cyrl"
+	Unknown         = "unknown"
+
+	// profileSize is the number of ranked n-grams kept in a profile.
+	profileSize = 300
+	// maxNGram is the longest n-gram a profile is built from.
+	maxNGram = 3
+	// sampleLimit is the number of bytes of a document used for detection.
+	sampleLimit = 16 * 1024
+	// minLetters is the number of letters needed for a reliable guess.
+	minLetters = 20
+)
+
+type profile map[string]int
+
+// latinProfiles are compared with documents written in Latin script, the
+// only Cyrillic language expected in the corpora is Serbian so Cyrillic
+// documents are recognized by script alone.
+var latinProfiles = map[string]profile{
+	English:      newProfile(englishSample),
+	SerbianLatin: newProfile(serbianSample),
+}
+
+// Matches reports whether a detected language matches filter, which may be a
+// full language tag or just its language part, so sr matches sr-Latn.
+func Matches(language, filter string) bool {
+	return strings.EqualFold(language, filter) ||
+		strings.HasPrefix(strings.ToLower(language), strings.ToLower(filter)+"-")
+}
+
+// Detect guesses the language of data with the out of place measure between
+// n-gram profiles.
+func Detect(data []byte) string {
+	if len(data) > sampleLimit {
+		data = data[:sampleLimit]
+		for len(data) > 0 && !utf8.RuneStart(data[len(data)-1]) {
+			data = data[:len(data)-1]
+		}
+	}
+
+	latin, cyrillic := 0, 0
+	for _, r := range string(data) {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	if latin+cyrillic < minLetters {
+		return Unknown
+	}
+	if cyrillic > latin {
+		return SerbianCyrillic
+	}
+
+	document := newProfile(string(data))
+
+	best, bestDistance := Unknown, -1
+	for language, p := range latinProfiles {
+		distance := document.distance(p)
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = language, distance
+		}
+	}
+	return best
+}
+
+func newProfile(text string) profile {
+	counts := make(map[string]int)
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		runes := []rune("_" + word + "_")
+		for n := 1; n <= maxNGram; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				counts[string(runes[i:i+n])]++
+			}
+		}
+	}
+
+	ngrams := make([]string, 0, len(counts))
+	for ngram := range counts {
+		ngrams = append(ngrams, ngram)
+	}
+	sort.Slice(ngrams, func(i, j int) bool {
+		if counts[ngrams[i]] == counts[ngrams[j]] {
+			return ngrams[i] < ngrams[j]
+		}
+		return counts[ngrams[i]] > counts[ngrams[j]]
+	})
+
+	if len(ngrams) > profileSize {
+		ngrams = ngrams[:profileSize]
+	}
+
+	p := make(profile, len(ngrams))
+	for rank, ngram := range ngrams {
+		p[ngram] = rank
+	}
+	return p
+}
+
+func (p profile) distance(other profile) int {
+	distance := 0
+	for ngram, rank := range p {
+		otherRank, ok := other[ngram]
+		if !ok {
+			distance += profileSize
+			continue
+		}
+		if rank > otherRank {
+			distance += rank - otherRank
+		} else {
+			distance += otherRank - rank
+		}
+	}
+	return distance
+}
@@ -0,0 +1,27 @@
+package lang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	for text, expected := range map[string]string{
+		"The committee will publish the final report on the project next week, after the members have reviewed it.": English,
+		"Komisija će sledeće nedelje objaviti konačni izveštaj o projektu, nakon što ga članovi pregledaju.":        SerbianLatin,
+		"Komisija ce sledece nedelje objaviti konacni izvestaj o projektu, nakon sto ga clanovi pregledaju.":        SerbianLatin,
+		"Комисија ће следеће недеље објавити коначни извештај о пројекту, након што га чланови прегледају.":         SerbianCyrillic,
+		"ok":  Unknown,
+		"123": Unknown,
+	} {
+		assert.Equal(t, expected, Detect([]byte(text)), text)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	assert.True(t, Matches(SerbianLatin, "sr"))
+	assert.True(t, Matches(SerbianCyrillic, "sr-cyrl"))
+	assert.False(t, Matches(English, "sr"))
+	assert.False(t, Matches(SerbianLatin, "s"))
+}
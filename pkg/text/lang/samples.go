@@ -0,0 +1,33 @@
+package lang
+
+// The samples below train the n-gram profiles of the Latin script languages.
+
+const englishSample = `The quick development of the city brought new people, new roads and new
+problems. Most of the houses were built during the last century, when the
+river was still used to carry goods from the mountains to the sea. People who
+live here today work in offices, shops and factories, and many of them travel
+every day to the capital. The government has promised to improve the public
+transport and to build a new hospital, but the work has not started yet.
+Children go to school in the morning and play in the park in the afternoon.
+In the evening the streets are full of people walking, talking and eating in
+small restaurants. There is a theatre, a museum and a library which is open
+every day except Sunday. Visitors often say that the old town is the most
+beautiful part of the city, with its narrow streets and colourful buildings.
+This report describes the results of the research and explains how the data
+was collected, what the main findings are and which questions remain open for
+further work. We would like to thank everyone who helped with this project.`
+
+const serbianSample = `Brzi razvoj grada doneo je nove ljude, nove puteve i nove probleme. Većina
+kuća izgrađena je tokom prošlog veka, kada se reka još koristila za prevoz
+robe sa planina do mora. Ljudi koji danas ovde žive rade u kancelarijama,
+prodavnicama i fabrikama, a mnogi od njih svakog dana putuju u prestonicu.
+Vlada je obećala da će poboljšati javni prevoz i izgraditi novu bolnicu, ali
+radovi još nisu počeli. Deca idu u školu ujutru i igraju se u parku posle
+podne. Uveče su ulice pune ljudi koji šetaju, razgovaraju i jedu u malim
+restoranima. Postoji pozorište, muzej i biblioteka koja je otvorena svakog
+dana osim nedelje. Posetioci često kažu da je stari grad najlepši deo grada,
+sa svojim uskim ulicama i šarenim zgradama. Ovaj izveštaj opisuje rezultate
+istraživanja i objašnjava kako su podaci prikupljeni, koji su glavni nalazi i
+koja pitanja ostaju otvorena za dalji rad. Želimo da se zahvalimo svima koji
+su pomogli u ovom projektu. Beograd je glavni grad Srbije i nalazi se na ušću
+Save u Dunav, a Novi Sad i Niš su takođe veliki gradovi.`
@@ -0,0 +1,23 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize lowercases a token and trims the punctuation around it.
+func Normalize(token string) string {
+	return strings.ToLower(strings.TrimFunc(token, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}))
+}
+
+func normalizeTokens(tokens []Token) []string {
+	words := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if word := Normalize(token.Text); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
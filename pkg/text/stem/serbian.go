@@ -0,0 +1,44 @@
+package stem
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// serbianSuffixes are the inflectional endings of Serbian nouns, adjectives
+// and verbs in Latin script, the longest matching one is stripped.
+var serbianSuffixes = []string{
+	"ovima", "evima", "ijega", "ijemu", "ijima",
+	"ovoj", "evoj", "ijeg", "ijem", "ijoj", "ijih", "ijim", "ujem", "ujes", "ujemo", "ujete", "uju",
+	"ama", "ima", "ega", "emu", "oga", "omu", "ovi", "evi", "ove", "eve", "ova", "eva", "iji", "ija", "ije", "iju",
+	"ati", "iti", "eti", "uti", "amo", "emo", "imo", "ate", "ete", "ite", "aju", "ala", "alo", "ali", "ila", "ilo", "ili",
+	"om", "em", "og", "eg", "oj", "ih", "im", "ao", "io", "eo", "aj",
+	"a", "e", "i", "o", "u",
+}
+
+// minSerbianStem is the minimum number of letters left after stripping.
+const minSerbianStem = 2
+
+// Serbian is a light suffix stripping stemmer for Serbian Latin, it expects
+// lowercase words.
+type Serbian struct{}
+
+func (Serbian) Stem(word string) string {
+	best := ""
+	for _, suffix := range serbianSuffixes {
+		if len(suffix) <= len(best) || !strings.HasSuffix(word, suffix) {
+			continue
+		}
+
+		stem := word[:len(word)-len(suffix)]
+		if utf8.RuneCountInString(stem) >= minSerbianStem && hasSerbianVowel(stem) {
+			best = suffix
+		}
+	}
+
+	return word[:len(word)-len(best)]
+}
+
+func hasSerbianVowel(stem string) bool {
+	return strings.ContainsAny(stem, "aeiour")
+}
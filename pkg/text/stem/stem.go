@@ -0,0 +1,32 @@
+package stem
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	NoneStemmer    = "none"
+	EnglishStemmer = "english"
+	SerbianStemmer = "serbian"
+)
+
+type Stemmer interface {
+	Stem(word string) string
+}
+
+// New returns the stemmer with the given name, nil for "none" or an empty
+// name.
+func New(name string) (Stemmer, error) {
+	switch strings.ToLower(name) {
+	case "", NoneStemmer:
+		return nil, nil
+	case EnglishStemmer, "porter":
+		return English{}, nil
+	case SerbianStemmer:
+		return Serbian{}, nil
+	default:
+		return nil, errors.Errorf("unknown stemmer %q", name)
+	}
+}
@@ -0,0 +1,50 @@
+package stem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnglish(t *testing.T) {
+	for word, expected := range map[string]string{
+		"running":        "run",
+		"runs":           "run",
+		"run":            "run",
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"agreed":         "agre",
+		"hopping":        "hop",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"conditional":    "condit",
+		"generalization": "gener",
+		"adjustment":     "adjust",
+		"controlling":    "control",
+		"is":             "is",
+	} {
+		assert.Equal(t, expected, English{}.Stem(word), word)
+	}
+}
+
+func TestSerbian(t *testing.T) {
+	s := Serbian{}
+	assert.Equal(t, s.Stem("grad"), s.Stem("gradovima"))
+	assert.Equal(t, s.Stem("knjiga"), s.Stem("knjige"))
+	assert.Equal(t, s.Stem("beograd"), s.Stem("beogradu"))
+	assert.Equal(t, "ne", s.Stem("ne"))
+}
+
+func TestNew(t *testing.T) {
+	s, err := New("")
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	s, err = New("English")
+	assert.NoError(t, err)
+	assert.IsType(t, English{}, s)
+
+	_, err = New("klingon")
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package stopwords
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	EnglishList = "english"
+	SerbianList = "serbian"
+)
+
+var English = []string{
+	"a", "about", "above", "after", "again", "against", "all", "am", "an", "and",
+	"any", "are", "as", "at", "be", "because", "been", "before", "being", "below",
+	"between", "both", "but", "by", "can", "could", "did", "do", "does", "doing",
+	"down", "during", "each", "few", "for", "from", "further", "had", "has", "have",
+	"having", "he", "her", "here", "hers", "herself", "him", "himself", "his", "how",
+	"i", "if", "in", "into", "is", "it", "its", "itself", "just", "me",
+	"more", "most", "my", "myself", "no", "nor", "not", "now", "of", "off",
+	"on", "once", "only", "or", "other", "our", "ours", "ourselves", "out", "over",
+	"own", "same", "she", "should", "so", "some", "such", "than", "that", "the",
+	"their", "theirs", "them", "themselves", "then", "there", "these", "they", "this", "those",
+	"through", "to", "too", "under", "until", "up", "very", "was", "we", "were",
+	"what", "when", "where", "which", "while", "who", "whom", "why", "will", "with",
+	"would", "you", "your", "yours", "yourself", "yourselves",
+}
+
+var Serbian = []string{
+	"a", "ako", "ali", "bi", "bih", "bila", "bili", "bilo", "bio", "bismo",
+	"biste", "biti", "da", "do", "duž", "ga", "hoće", "hoću", "i", "ih",
+	"ili", "iz", "ja", "je", "jeste", "joj", "još", "ju", "k", "kad",
+	"kada", "kako", "kao", "koja", "koje", "koji", "kojih", "kojim", "kojima", "kome",
+	"kroz", "li", "me", "mene", "meni", "mi", "mimo", "moj", "moja", "moje",
+	"mu", "na", "nad", "nakon", "nam", "nama", "nas", "naš", "naša", "naše",
+	"ne", "nego", "neka", "nešto", "ni", "nije", "nikoga", "ništa", "njega", "njemu",
+	"njen", "njih", "njihov", "njoj", "o", "od", "odmah", "on", "ona", "one",
+	"oni", "ono", "pa", "po", "pod", "pored", "posle", "pre", "preko", "prema",
+	"sa", "sam", "se", "si", "sebe", "smo", "ste", "su", "sve", "svi",
+	"svoj", "svoja", "svoje", "šta", "ta", "tada", "taj", "te", "ti", "to",
+	"toga", "tom", "tu", "u", "uz", "vam", "vas", "vaš", "već", "vi",
+	"za", "zar", "će", "ćemo", "ćete", "ću", "što",
+}
+
+var lists = map[string][]string{
+	EnglishList: English,
+	SerbianList: Serbian,
+}
+
+// Load builds a stop word set out of the named built in lists and the words
+// of file, one per line, if file isn't empty.
+func Load(names []string, file string) (map[string]struct{}, error) {
+	set := make(map[string]struct{})
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		list, ok := lists[name]
+		if !ok {
+			return nil, errors.Errorf("unknown stop word list %q", name)
+		}
+		for _, word := range list {
+			set[word] = struct{}{}
+		}
+	}
+
+	if file == "" {
+		return set, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't open stop word file")
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if word := strings.ToLower(strings.TrimSpace(scanner.Text())); word != "" {
+			set[word] = struct{}{}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "couldn't read stop word file")
+	}
+	return set, nil
+}
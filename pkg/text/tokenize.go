@@ -0,0 +1,44 @@
+package text
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+type Token struct {
+	Text string
+	// Offset is the byte offset of the token within the tokenized data.
+	Offset int
+	// Line is the one based line number the token starts on.
+	Line int
+}
+
+// Tokenize splits data around whitespace the same way strings.Fields does,
+// keeping the position of every token.
+func Tokenize(data []byte) []Token {
+	tokens := make([]Token, 0, len(data)/6)
+	line := 1
+	start := -1
+
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				tokens = append(tokens, Token{Text: string(data[start:i]), Offset: start, Line: line})
+				start = -1
+			}
+			if r == '\n' {
+				line++
+			}
+		} else if start < 0 {
+			start = i
+		}
+		i += size
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, Token{Text: string(data[start:]), Offset: start, Line: line})
+	}
+
+	return tokens
+}
@@ -0,0 +1,59 @@
+// Package translit transliterates Serbian Cyrillic to Latin so mixed-script
+// corpora can be matched against a single spelling.
+package translit
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'ђ': "đ", 'е': "e",
+	'ж': "ž", 'з': "z", 'и': "i", 'ј': "j", 'к': "k", 'л': "l", 'љ': "lj",
+	'м': "m", 'н': "n", 'њ': "nj", 'о': "o", 'п': "p", 'р': "r", 'с': "s",
+	'т': "t", 'ћ': "ć", 'у': "u", 'ф': "f", 'х': "h", 'ц': "c", 'ч': "č",
+	'џ': "dž", 'ш': "š",
+}
+
+// Latin transliterates the Serbian Cyrillic letters of s to Latin, other
+// runes are kept as they are. Uppercase digraphs are written as Lj, Nj and
+// Dž, or fully uppercase when the following letter is uppercase too.
+func Latin(s string) string {
+	if !hasCyrillic(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i, r := range s {
+		latin, ok := cyrillic[unicode.ToLower(r)]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if !unicode.IsUpper(r) {
+			b.WriteString(latin)
+			continue
+		}
+
+		next, _ := utf8.DecodeRuneInString(s[i+utf8.RuneLen(r):])
+		if unicode.IsUpper(next) {
+			b.WriteString(strings.ToUpper(latin))
+			continue
+		}
+		first, size := utf8.DecodeRuneInString(latin)
+		b.WriteRune(unicode.ToUpper(first))
+		b.WriteString(latin[size:])
+	}
+	return b.String()
+}
+
+func hasCyrillic(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
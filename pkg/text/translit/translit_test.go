@@ -0,0 +1,22 @@
+package translit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatin(t *testing.T) {
+	for cyrillic, latin := range map[string]string{
+		"Београд":   "Beograd",
+		"љубав":     "ljubav",
+		"Њујорк":    "Njujork",
+		"ЏЕП":       "DŽEP",
+		"Џеп":       "Džep",
+		"ђак ћуфта": "đak ćufta",
+		"Beograd":   "Beograd",
+		"Ниш 2024.": "Niš 2024.",
+	} {
+		assert.Equal(t, latin, Latin(cyrillic), cyrillic)
+	}
+}